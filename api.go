@@ -0,0 +1,123 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	MainnetAPIURL = "https://api.hyperliquid.xyz"
+	TestnetAPIURL = "https://api.hyperliquid-testnet.xyz"
+
+	defaultHTTPTimeout = 10 * time.Second
+)
+
+// ErrUnknownNetwork is returned by IsMainnetURL for a host that is neither the
+// mainnet nor the testnet API
+var ErrUnknownNetwork = errors.New("unrecognized Hyperliquid host")
+
+const (
+	mainnetHost = "api.hyperliquid.xyz"
+	testnetHost = "api.hyperliquid-testnet.xyz"
+)
+
+// IsMainnetURL reports whether baseURL (REST or WebSocket) points at mainnet or
+// testnet. An empty baseURL is MainnetAPIURL, as for the clients. Any other host,
+// such as a local proxy, returns ErrUnknownNetwork so the caller must choose
+func IsMainnetURL(baseURL string) (bool, error) {
+	if baseURL == "" {
+		return true, nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false, fmt.Errorf("parsing base URL: %w", err)
+	}
+
+	switch strings.ToLower(u.Hostname()) {
+	case mainnetHost:
+		return true, nil
+	case testnetHost:
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: %q", ErrUnknownNetwork, baseURL)
+}
+
+// HTTPError is returned when the API answers with a non-2xx status code
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %d: %s", e.StatusCode, e.Body)
+}
+
+// api is the JSON-over-HTTP transport shared by the Info and Exchange clients
+type api struct {
+	baseURL    string
+	httpClient *http.Client
+	limiter    *RateLimiter
+}
+
+func newAPI(baseURL string) api {
+	if baseURL == "" {
+		baseURL = MainnetAPIURL
+	}
+
+	return api{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// post sends body as JSON to path and returns the raw response body. When a rate
+// limiter is configured, weight is acquired from it first
+func (a *api) post(ctx context.Context, path string, weight int, body interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	return a.postRaw(ctx, path, weight, encoded)
+}
+
+// postRaw is post for a body that is already JSON encoded
+func (a *api) postRaw(ctx context.Context, path string, weight int, encoded []byte) ([]byte, error) {
+	if a.limiter != nil {
+		if err := a.limiter.Acquire(ctx, weight); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+path, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,45 @@
+package hyperliquid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsMainnetURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    bool
+		wantErr error
+	}{
+		{"empty", "", true, nil},
+		{"mainnet", MainnetAPIURL, true, nil},
+		{"testnet", TestnetAPIURL, false, nil},
+		{"mainnet trailing slash", MainnetAPIURL + "/", true, nil},
+		{"testnet trailing slash", TestnetAPIURL + "/", false, nil},
+		{"mainnet upper case", "https://API.Hyperliquid.xyz", true, nil},
+		{"testnet websocket", "wss://api.hyperliquid-testnet.xyz/ws", false, nil},
+		{"local proxy", "http://localhost:3001", false, ErrUnknownNetwork},
+		{"custom host", "https://hl.example.com", false, ErrUnknownNetwork},
+		{"lookalike host", "https://api.hyperliquid.xyz.example.com", false, ErrUnknownNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsMainnetURL(tt.baseURL)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsMainnetURL(%q) = %v, want %v", tt.baseURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMainnetURLUnparsable(t *testing.T) {
+	_, err := IsMainnetURL("://no-scheme")
+	if err == nil || errors.Is(err, ErrUnknownNetwork) {
+		t.Errorf("err = %v, want a parse error", err)
+	}
+}
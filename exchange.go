@@ -0,0 +1,297 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cgaspart/hyperliquid-go/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrAssetMapNotSet = errors.New("asset map not set")
+
+// DryRunStatus is the OrderResponse status returned in dry-run mode
+const DryRunStatus = "dryRun"
+
+// Exchange signs actions with a wallet and submits them to the /exchange endpoint
+type Exchange struct {
+	api
+	wallet       utils.Wallet
+	isMainnet    bool
+	vaultAddress string
+	assetMap     map[string]int
+	retryPolicy  RetryPolicy
+	nonces       *utils.NonceManager
+	dryRun       bool
+	checksums    bool
+}
+
+// NewExchange creates an Exchange client. An empty baseURL defaults to MainnetAPIURL.
+// For the mainnet and testnet APIs the network actions are signed for is derived
+// from baseURL by IsMainnetURL; isMainnet is only used for other hosts, such as a
+// local proxy
+func NewExchange(wallet utils.Wallet, baseURL string, isMainnet bool) *Exchange {
+	if derived, err := IsMainnetURL(baseURL); err == nil {
+		isMainnet = derived
+	}
+
+	return &Exchange{
+		api:         newAPI(baseURL),
+		wallet:      wallet,
+		isMainnet:   isMainnet,
+		retryPolicy: NoRetryPolicy(),
+		nonces:      utils.NewNonceManager(),
+	}
+}
+
+// NewExchangeFromURL is NewExchange for the mainnet and testnet APIs only: it
+// returns ErrUnknownNetwork for hosts IsMainnetURL does not recognize. Use
+// NewExchange to choose the network for those
+func NewExchangeFromURL(wallet utils.Wallet, baseURL string) (*Exchange, error) {
+	isMainnet, err := IsMainnetURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewExchange(wallet, baseURL, isMainnet), nil
+}
+
+// SetHTTPClient replaces the HTTP client used for submissions
+func (e *Exchange) SetHTTPClient(client *http.Client) {
+	e.httpClient = client
+}
+
+// SetRateLimiter makes every submission consult limiter first. Pass nil to disable
+func (e *Exchange) SetRateLimiter(limiter *RateLimiter) {
+	e.limiter = limiter
+}
+
+// SetRetryPolicy sets how failed submissions are retried
+func (e *Exchange) SetRetryPolicy(policy RetryPolicy) {
+	e.retryPolicy = policy
+}
+
+// SetNonceManager replaces the nonce source, e.g. to share one manager between
+// several clients signing with the same wallet. Pass nil to go back to a fresh
+// manager of the client's own
+func (e *Exchange) SetNonceManager(nonces *utils.NonceManager) {
+	if nonces == nil {
+		nonces = utils.NewNonceManager()
+	}
+	e.nonces = nonces
+}
+
+// SetDryRun makes every action be signed but not submitted: the returned
+// OrderResponse has DryRunStatus and carries the exact JSON body in Payload
+func (e *Exchange) SetDryRun(dryRun bool) {
+	e.dryRun = dryRun
+}
+
+// SetChecksumValidation makes the client reject mixed-case vault and destination
+// addresses whose casing fails the EIP-55 checksum, which usually means a typo
+func (e *Exchange) SetChecksumValidation(enabled bool) {
+	e.checksums = enabled
+}
+
+// SetVaultAddress makes every L1 action act on behalf of the given vault or
+// sub-account. An empty address resets to the wallet's own account
+func (e *Exchange) SetVaultAddress(vaultAddress string) error {
+	if vaultAddress != "" {
+		if err := e.validateVaultAddress(vaultAddress, "vaultAddress"); err != nil {
+			return err
+		}
+	}
+	e.vaultAddress = vaultAddress
+	return nil
+}
+
+// WithVault returns a copy of the client whose L1 actions trade on behalf of the
+// vault at vaultAddress, which the wallet must lead. The copy shares the transport,
+// rate limiter and nonce manager with e
+func (e *Exchange) WithVault(vaultAddress string) (*Exchange, error) {
+	return e.withAccount(vaultAddress, "vault")
+}
+
+// WithSubAccount returns a copy of the client whose L1 actions trade on behalf of
+// the sub-account at subAccount, which the wallet must own. The exchange routes
+// both through the vaultAddress field; the distinction only documents intent
+func (e *Exchange) WithSubAccount(subAccount string) (*Exchange, error) {
+	return e.withAccount(subAccount, "sub-account")
+}
+
+func (e *Exchange) withAccount(address string, kind string) (*Exchange, error) {
+	if err := e.validateVaultAddress(address, kind); err != nil {
+		return nil, err
+	}
+
+	scoped := *e
+	scoped.vaultAddress = address
+	return &scoped, nil
+}
+
+// validateVaultAddress is the single check applied to every address placed in the
+// vaultAddress field
+func (e *Exchange) validateVaultAddress(address string, kind string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("%w: %s %q", utils.ErrInvalidAddress, kind, address)
+	}
+	if common.HexToAddress(address) == (common.Address{}) {
+		return fmt.Errorf("%w: %s must not be the zero address", utils.ErrInvalidAddress, kind)
+	}
+	if e.checksums {
+		if err := utils.ValidateChecksumAddress(address); err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// SetAssetMap sets the coin to asset index map used to build actions
+func (e *Exchange) SetAssetMap(assetMap map[string]int) {
+	e.assetMap = assetMap
+}
+
+func (e *Exchange) PlaceOrders(ctx context.Context, orders []utils.OrderRequest, grouping utils.GroupingType) (utils.OrderResponse, error) {
+	if e.assetMap == nil {
+		return utils.OrderResponse{}, ErrAssetMapNotSet
+	}
+
+	wires, err := utils.BatchOrdersToWire(orders, e.assetMap)
+	if err != nil {
+		return utils.OrderResponse{}, err
+	}
+
+	action, err := utils.OrderWiresToOrderActionGrouped(wires, grouping, "")
+	if err != nil {
+		return utils.OrderResponse{}, err
+	}
+
+	return e.submitL1Action(ctx, action, ExchangeActionWeight(len(wires)))
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, cancels []utils.CancelRequest) (utils.OrderResponse, error) {
+	if e.assetMap == nil {
+		return utils.OrderResponse{}, ErrAssetMapNotSet
+	}
+
+	action, err := utils.CreateCancelAction(cancels, e.assetMap)
+	if err != nil {
+		return utils.OrderResponse{}, err
+	}
+
+	return e.submitL1Action(ctx, action, ExchangeActionWeight(len(action.Cancels)))
+}
+
+func (e *Exchange) CancelOrdersByCloid(ctx context.Context, cancels []utils.CancelByCloidRequest) (utils.OrderResponse, error) {
+	if e.assetMap == nil {
+		return utils.OrderResponse{}, ErrAssetMapNotSet
+	}
+
+	action, err := utils.CreateCancelByCloidAction(cancels, e.assetMap)
+	if err != nil {
+		return utils.OrderResponse{}, err
+	}
+
+	return e.submitL1Action(ctx, action, ExchangeActionWeight(len(action.Cancels)))
+}
+
+func (e *Exchange) ModifyOrders(ctx context.Context, modifies []utils.ModifyRequest) (utils.OrderResponse, error) {
+	if e.assetMap == nil {
+		return utils.OrderResponse{}, ErrAssetMapNotSet
+	}
+
+	action, err := utils.CreateBatchModifyAction(modifies, e.assetMap)
+	if err != nil {
+		return utils.OrderResponse{}, err
+	}
+
+	return e.submitL1Action(ctx, action, ExchangeActionWeight(len(action.Modifies)))
+}
+
+func (e *Exchange) UpdateLeverage(ctx context.Context, coin string, isCross bool, leverage int) (utils.OrderResponse, error) {
+	if e.assetMap == nil {
+		return utils.OrderResponse{}, ErrAssetMapNotSet
+	}
+
+	asset, ok := utils.LookupAsset(e.assetMap, coin)
+	if !ok {
+		return utils.OrderResponse{}, fmt.Errorf("unknown asset: %s", coin)
+	}
+
+	action, err := utils.CreateUpdateLeverageAction(asset, isCross, leverage)
+	if err != nil {
+		return utils.OrderResponse{}, err
+	}
+
+	return e.submitL1Action(ctx, action, ExchangeActionWeight(0))
+}
+
+// Withdraw withdraws USDC from the bridge to destination. The action time doubles
+// as the submission nonce
+func (e *Exchange) Withdraw(ctx context.Context, destination string, amount string) (utils.OrderResponse, error) {
+	if e.checksums {
+		if err := utils.ValidateChecksumAddress(destination); err != nil {
+			return utils.OrderResponse{}, fmt.Errorf("destination: %w", err)
+		}
+	}
+
+	return e.withRetry(ctx, func(nonce uint64) (utils.OrderResponse, error) {
+		action := utils.CreateWithdrawActionStruct(destination, amount, nonce).ToMap()
+		action = utils.PrepareUserSignedAction(action, e.isMainnet)
+
+		sig, err := utils.SignWithdrawFromBridgeAction(e.wallet, action, e.isMainnet)
+		if err != nil {
+			return utils.OrderResponse{}, fmt.Errorf("signing withdraw: %w", err)
+		}
+
+		return e.postAction(ctx, action, nonce, sig, "", ExchangeActionWeight(0))
+	})
+}
+
+// submitL1Action signs action with a fresh nonce and submits it, retrying
+// according to the retry policy
+func (e *Exchange) submitL1Action(ctx context.Context, action interface{}, weight int) (utils.OrderResponse, error) {
+	return e.withRetry(ctx, func(nonce uint64) (utils.OrderResponse, error) {
+		sig, err := utils.SignL1Action(e.wallet, action, e.vaultAddress, nonce, e.isMainnet)
+		if err != nil {
+			return utils.OrderResponse{}, fmt.Errorf("signing action: %w", err)
+		}
+
+		return e.postAction(ctx, action, nonce, sig, e.vaultAddress, weight)
+	})
+}
+
+// postAction assembles the /exchange payload and parses the response
+func (e *Exchange) postAction(ctx context.Context, action interface{}, nonce uint64, sig utils.Signature, vaultAddress string, weight int) (utils.OrderResponse, error) {
+	return e.postSigned(ctx, utils.SignedAction{
+		Action:       action,
+		Nonce:        nonce,
+		VaultAddress: vaultAddress,
+		Signature:    sig,
+	}, weight)
+}
+
+// postSigned submits a signed action, or returns its payload in dry-run mode
+func (e *Exchange) postSigned(ctx context.Context, signed utils.SignedAction, weight int) (utils.OrderResponse, error) {
+	encoded, err := json.Marshal(signed.ToPayload())
+	if err != nil {
+		return utils.OrderResponse{}, fmt.Errorf("encoding request: %w", err)
+	}
+
+	if e.dryRun {
+		return utils.OrderResponse{Status: DryRunStatus, Payload: encoded}, nil
+	}
+
+	body, err := e.postRaw(ctx, "/exchange", weight, encoded)
+	if err != nil {
+		return utils.OrderResponse{}, err
+	}
+
+	resp, err := utils.ParseOrderResponse(body)
+	resp.Payload = encoded
+
+	return resp, err
+}
@@ -0,0 +1,50 @@
+package hyperliquid
+
+import (
+	"testing"
+
+	"github.com/cgaspart/hyperliquid-go/utils"
+)
+
+func TestSetNonceManager(t *testing.T) {
+	e := NewExchange(nil, "", true)
+
+	shared := utils.NewNonceManager()
+	e.SetNonceManager(shared)
+	if e.nonces != shared {
+		t.Fatal("shared manager not installed")
+	}
+
+	e.SetNonceManager(nil)
+	if e.nonces == nil || e.nonces == shared {
+		t.Fatalf("SetNonceManager(nil) left %p, want a fresh manager", e.nonces)
+	}
+	first := e.nonces.Next()
+	if next := e.nonces.Next(); next <= first {
+		t.Errorf("Next = %d after %d", next, first)
+	}
+}
+
+func TestNewExchangeNetwork(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseURL   string
+		isMainnet bool
+		want      bool
+	}{
+		{"mainnet", MainnetAPIURL, true, true},
+		{"testnet", TestnetAPIURL, false, false},
+		{"testnet flagged as mainnet", TestnetAPIURL, true, false},
+		{"mainnet flagged as testnet", MainnetAPIURL + "/", false, true},
+		{"local proxy to mainnet", "http://localhost:3001", true, true},
+		{"local proxy to testnet", "http://localhost:3001", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewExchange(nil, tt.baseURL, tt.isMainnet).isMainnet; got != tt.want {
+				t.Errorf("isMainnet = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
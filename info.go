@@ -0,0 +1,163 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cgaspart/hyperliquid-go/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Info performs read-only queries against the /info endpoint
+type Info struct {
+	api
+}
+
+// NewInfo creates an Info client. An empty baseURL defaults to MainnetAPIURL
+func NewInfo(baseURL string) *Info {
+	return &Info{api: newAPI(baseURL)}
+}
+
+// SetHTTPClient replaces the HTTP client used for queries
+func (i *Info) SetHTTPClient(client *http.Client) {
+	i.httpClient = client
+}
+
+// SetRateLimiter makes every query consult limiter first. Pass nil to disable
+func (i *Info) SetRateLimiter(limiter *RateLimiter) {
+	i.limiter = limiter
+}
+
+// Query posts an arbitrary {"type": ...} request and returns the raw JSON response
+func (i *Info) Query(ctx context.Context, request map[string]interface{}) (json.RawMessage, error) {
+	requestType, _ := request["type"].(string)
+	body, err := i.post(ctx, "/info", InfoRequestWeight(requestType), request)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+// UserState returns the clearinghouse state (margin summary and positions) of addr
+func (i *Info) UserState(ctx context.Context, addr string) (utils.UserState, error) {
+	if !common.IsHexAddress(addr) {
+		return utils.UserState{}, fmt.Errorf("%w: %s", utils.ErrInvalidAddress, addr)
+	}
+	body, err := i.Query(ctx, map[string]interface{}{"type": "clearinghouseState", "user": addr})
+	if err != nil {
+		return utils.UserState{}, err
+	}
+	return utils.ParseUserState(body)
+}
+
+// OpenOrders returns the open orders of addr
+func (i *Info) OpenOrders(ctx context.Context, addr string) ([]utils.OpenOrder, error) {
+	if !common.IsHexAddress(addr) {
+		return nil, fmt.Errorf("%w: %s", utils.ErrInvalidAddress, addr)
+	}
+	body, err := i.Query(ctx, map[string]interface{}{"type": "openOrders", "user": addr})
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseOpenOrders(body)
+}
+
+// FrontendOpenOrders returns the open orders of addr with their original size,
+// reduce-only flag and order type
+func (i *Info) FrontendOpenOrders(ctx context.Context, addr string) ([]utils.OpenOrder, error) {
+	if !common.IsHexAddress(addr) {
+		return nil, fmt.Errorf("%w: %s", utils.ErrInvalidAddress, addr)
+	}
+	body, err := i.Query(ctx, map[string]interface{}{"type": "frontendOpenOrders", "user": addr})
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseOpenOrders(body)
+}
+
+// UserFills returns the most recent fills of addr
+func (i *Info) UserFills(ctx context.Context, addr string) ([]utils.Fill, error) {
+	if !common.IsHexAddress(addr) {
+		return nil, fmt.Errorf("%w: %s", utils.ErrInvalidAddress, addr)
+	}
+	body, err := i.Query(ctx, map[string]interface{}{"type": "userFills", "user": addr})
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseFills(body)
+}
+
+// UserFillsByTime returns the fills of addr from startTime (ms) on. A zero endTime
+// means up to now. Page forward with utils.LatestFillTime
+func (i *Info) UserFillsByTime(ctx context.Context, addr string, startTime, endTime int64) ([]utils.Fill, error) {
+	if !common.IsHexAddress(addr) {
+		return nil, fmt.Errorf("%w: %s", utils.ErrInvalidAddress, addr)
+	}
+	request := map[string]interface{}{"type": "userFillsByTime", "user": addr, "startTime": startTime}
+	if endTime != 0 {
+		request["endTime"] = endTime
+	}
+	body, err := i.Query(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseFills(body)
+}
+
+// Meta returns the decoded perp universe, with max leverage and margin tables.
+// Meta.AssetMap and utils.NewAssetRegistryFromMeta build on it
+func (i *Info) Meta(ctx context.Context) (utils.Meta, error) {
+	body, err := i.Query(ctx, map[string]interface{}{"type": "meta"})
+	if err != nil {
+		return utils.Meta{}, err
+	}
+	return utils.ParseMeta(body)
+}
+
+// SpotMeta returns the decoded spot universe and tokens. SpotMeta.AssetMaps and
+// utils.NewAssetRegistryFromMeta build on it
+func (i *Info) SpotMeta(ctx context.Context) (utils.SpotMeta, error) {
+	body, err := i.Query(ctx, map[string]interface{}{"type": "spotMeta"})
+	if err != nil {
+		return utils.SpotMeta{}, err
+	}
+	return utils.ParseSpotMeta(body)
+}
+
+// AllMids returns the mid price of every coin
+func (i *Info) AllMids(ctx context.Context) (utils.Mids, error) {
+	body, err := i.Query(ctx, map[string]interface{}{"type": "allMids"})
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseAllMids(body)
+}
+
+// L2Book returns the level 2 order book snapshot of coin
+func (i *Info) L2Book(ctx context.Context, coin string) (utils.L2Book, error) {
+	if coin == "" {
+		return utils.L2Book{}, fmt.Errorf("coin must be specified")
+	}
+	body, err := i.Query(ctx, map[string]interface{}{"type": "l2Book", "coin": coin})
+	if err != nil {
+		return utils.L2Book{}, err
+	}
+	return utils.ParseL2Book(body)
+}
+
+// AssetRegistry fetches meta and spotMeta and builds a registry covering perp and spot assets
+func (i *Info) AssetRegistry(ctx context.Context) (*utils.AssetRegistry, error) {
+	meta, err := i.Meta(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching meta: %w", err)
+	}
+
+	spotMeta, err := i.SpotMeta(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching spot meta: %w", err)
+	}
+
+	return utils.NewAssetRegistryFromMeta(meta, &spotMeta)
+}
@@ -0,0 +1,130 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWeightPerMinute is the documented REST weight budget per IP
+	DefaultWeightPerMinute = 1200
+
+	exchangeBatchWeightStep = 40
+)
+
+// RateLimitError is returned by a non-blocking RateLimiter when a request would
+// exceed the remaining budget
+type RateLimitError struct {
+	Weight     int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded: request weight %d, remaining %d, retry after %s",
+		e.Weight, e.Remaining, e.RetryAfter)
+}
+
+// RateLimiter is a token bucket holding a per-minute request weight budget that
+// refills continuously. In blocking mode Acquire waits for budget to become
+// available; otherwise it fails fast with a *RateLimitError
+type RateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64 // weight refilled per second
+	tokens   float64
+	last     time.Time
+	block    bool
+}
+
+// NewRateLimiter creates a limiter with a full budget of weightPerMinute
+func NewRateLimiter(weightPerMinute int, block bool) *RateLimiter {
+	return &RateLimiter{
+		capacity: float64(weightPerMinute),
+		rate:     float64(weightPerMinute) / time.Minute.Seconds(),
+		tokens:   float64(weightPerMinute),
+		last:     time.Now(),
+		block:    block,
+	}
+}
+
+// Acquire consumes weight from the budget
+func (r *RateLimiter) Acquire(ctx context.Context, weight int) error {
+	for {
+		wait, err := r.tryAcquire(weight)
+		if err != nil || wait == 0 {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// tryAcquire consumes weight if available. Otherwise it returns how long to wait in
+// blocking mode, or a *RateLimitError in non-blocking mode
+func (r *RateLimiter) tryAcquire(weight int) (time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if float64(weight) > r.capacity {
+		return 0, fmt.Errorf("request weight %d exceeds limiter capacity %.0f", weight, r.capacity)
+	}
+
+	r.refill()
+	if r.tokens >= float64(weight) {
+		r.tokens -= float64(weight)
+		return 0, nil
+	}
+
+	wait := time.Duration((float64(weight) - r.tokens) / r.rate * float64(time.Second))
+	if !r.block {
+		return 0, &RateLimitError{
+			Weight:     weight,
+			Remaining:  int(r.tokens),
+			RetryAfter: wait,
+		}
+	}
+	return wait, nil
+}
+
+// Remaining returns the weight currently available
+func (r *RateLimiter) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refill()
+	return int(r.tokens)
+}
+
+// refill must be called with r.mu held
+func (r *RateLimiter) refill() {
+	now := time.Now()
+	r.tokens = math.Min(r.capacity, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+}
+
+// ExchangeActionWeight returns the weight of an /exchange request acting on
+// batchLen orders or cancels
+func ExchangeActionWeight(batchLen int) int {
+	return 1 + batchLen/exchangeBatchWeightStep
+}
+
+// InfoRequestWeight returns the weight of an /info request of the given type
+func InfoRequestWeight(requestType string) int {
+	switch requestType {
+	case "l2Book", "allMids", "clearinghouseState", "orderStatus", "spotClearinghouseState", "exchangeStatus":
+		return 2
+	case "userRole":
+		return 60
+	}
+	return 20
+}
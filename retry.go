@@ -0,0 +1,105 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cgaspart/hyperliquid-go/utils"
+)
+
+// RetryPolicy controls how the Exchange client retries failed submissions. Every
+// retry uses a fresh nonce and a fresh signature
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// Retryable reports whether err is transient and safe to retry
+	Retryable func(err error) bool
+	// Backoff returns the delay before the given retry (starting at 1)
+	Backoff func(retry int) time.Duration
+}
+
+// NoRetryPolicy submits every action exactly once. It is the Exchange default
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// DefaultRetryPolicy retries transient errors up to 3 times in total with jittered
+// exponential backoff starting at 200ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   IsRetryableError,
+		Backoff:     JitteredBackoff(200*time.Millisecond, 5*time.Second),
+	}
+}
+
+// JitteredBackoff returns a Backoff doubling base on every retry, capped at max,
+// with full jitter. Retries below 1 are treated as the first retry
+func JitteredBackoff(base, max time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		if retry < 1 {
+			retry = 1
+		}
+
+		// Doubling past max (or past the int64 range) yields max
+		delay := max
+		if shift := retry - 1; shift < 63 && base <= max>>shift {
+			delay = base << shift
+		}
+		if delay <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+}
+
+// IsRetryableError reports whether err is a transient failure: a 5xx or 429 HTTP
+// status, or a recoverable rejection of the nonce. Business errors such as
+// insufficient margin or tick size violations are not retryable
+func IsRetryableError(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError ||
+			httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var nonceErr *utils.NonceError
+	if errors.As(err, &nonceErr) {
+		return nonceErr.Recoverable()
+	}
+
+	if errors.Is(err, utils.ErrExchangeRejected) {
+		return strings.Contains(strings.ToLower(err.Error()), "nonce")
+	}
+
+	return false
+}
+
+// withRetry runs submit with a fresh nonce until it succeeds, fails with a
+// non-retryable error or the policy's attempts are exhausted
+func (e *Exchange) withRetry(ctx context.Context, submit func(nonce uint64) (utils.OrderResponse, error)) (utils.OrderResponse, error) {
+	policy := e.retryPolicy
+	for attempt := 1; ; attempt++ {
+		resp, err := submit(e.nonces.Next())
+		if err == nil || attempt >= policy.MaxAttempts || policy.Retryable == nil || !policy.Retryable(err) {
+			return resp, err
+		}
+
+		var delay time.Duration
+		if policy.Backoff != nil {
+			delay = policy.Backoff(attempt)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cgaspart/hyperliquid-go/utils"
+)
+
+func TestJitteredBackoffBounds(t *testing.T) {
+	const (
+		base    = 100 * time.Millisecond
+		ceiling = time.Second
+	)
+	backoff := JitteredBackoff(base, ceiling)
+
+	tests := []struct {
+		retry int
+		limit time.Duration
+	}{
+		{retry: -3, limit: base},
+		{retry: 0, limit: base},
+		{retry: 1, limit: base},
+		{retry: 2, limit: 2 * base},
+		{retry: 3, limit: 4 * base},
+		{retry: 4, limit: 8 * base},
+		{retry: 5, limit: ceiling},
+		{retry: 63, limit: ceiling},
+		{retry: 64, limit: ceiling},
+		{retry: 1000, limit: ceiling},
+	}
+
+	for _, tt := range tests {
+		var largest time.Duration
+		for i := 0; i < 2000; i++ {
+			d := backoff(tt.retry)
+			if d < 0 || d > tt.limit {
+				t.Fatalf("retry %d: delay %v outside [0, %v]", tt.retry, d, tt.limit)
+			}
+			largest = max(largest, d)
+		}
+		// Full jitter spreads delays over the whole range
+		if largest < tt.limit/2 {
+			t.Errorf("retry %d: largest of 2000 delays %v, want close to %v", tt.retry, largest, tt.limit)
+		}
+	}
+}
+
+func TestJitteredBackoffHugeBase(t *testing.T) {
+	backoff := JitteredBackoff(time.Duration(1)<<62, time.Hour)
+	for _, retry := range []int{1, 2, 3} {
+		if d := backoff(retry); d < 0 || d > time.Hour {
+			t.Errorf("retry %d: delay %v outside [0, 1h]", retry, d)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	retryable := &HTTPError{StatusCode: http.StatusServiceUnavailable}
+	business := errors.New("insufficient margin")
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   IsRetryableError,
+		Backoff:     func(int) time.Duration { return 0 },
+	}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", []error{nil}, 1, nil},
+		{"non-retryable stops", []error{business, nil}, 1, business},
+		{"retryable then success", []error{retryable, nil}, 2, nil},
+		{"retryable then non-retryable", []error{retryable, business, nil}, 2, business},
+		{"attempts exhausted", []error{retryable, retryable, retryable, nil}, 3, retryable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExchange(nil, "", true)
+			e.SetRetryPolicy(policy)
+
+			var nonces []uint64
+			_, err := e.withRetry(context.Background(), func(nonce uint64) (utils.OrderResponse, error) {
+				nonces = append(nonces, nonce)
+				return utils.OrderResponse{}, tt.errs[len(nonces)-1]
+			})
+
+			if len(nonces) != tt.wantCalls {
+				t.Errorf("submitted %d times, want %d", len(nonces), tt.wantCalls)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			for i := 1; i < len(nonces); i++ {
+				if nonces[i] <= nonces[i-1] {
+					t.Errorf("retry reused nonce %d", nonces[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsOnCancel(t *testing.T) {
+	e := NewExchange(nil, "", true)
+	e.SetRetryPolicy(RetryPolicy{
+		MaxAttempts: 5,
+		Retryable:   func(error) bool { return true },
+		Backoff:     func(int) time.Duration { return time.Hour },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := e.withRetry(ctx, func(uint64) (utils.OrderResponse, error) {
+		calls++
+		cancel()
+		return utils.OrderResponse{}, errors.New("transient")
+	})
+	if calls != 1 || err == nil {
+		t.Errorf("calls = %d, err = %v; want 1 call and the submission error", calls, err)
+	}
+}
@@ -3,11 +3,35 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// WithdrawFeeUSD is the flat fee the bridge deducts from every withdrawal
+const WithdrawFeeUSD = 1.0
+
+var amountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ValidateAmountString checks that amount is a plain non-negative decimal string
+// such as "1000" or "0.5", without sign, exponent or digit separators
+func ValidateAmountString(amount string) error {
+	if !amountPattern.MatchString(amount) {
+		return fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	return nil
+}
+
+// validateActionAmount applies ValidateAmountString to the action's amount field
+func validateActionAmount(action map[string]interface{}) error {
+	amount, ok := action["amount"].(string)
+	if !ok {
+		return fmt.Errorf("%w: amount must be a string", ErrInvalidAmount)
+	}
+	return ValidateAmountString(amount)
+}
+
 func SignUSDTransferAction(wallet Wallet, action map[string]interface{}, isMainnet bool) (Signature, error) {
 	if _, ok := action["destination"]; !ok {
 		return Signature{}, errors.New("missing required field: destination")
@@ -18,6 +42,9 @@ func SignUSDTransferAction(wallet Wallet, action map[string]interface{}, isMainn
 	if _, ok := action["time"]; !ok {
 		return Signature{}, errors.New("missing required field: time")
 	}
+	if err := validateActionAmount(action); err != nil {
+		return Signature{}, err
+	}
 
 	destination, ok := action["destination"].(string)
 	if ok && !common.IsHexAddress(destination) {
@@ -40,6 +67,9 @@ func SignSpotTransferAction(wallet Wallet, action map[string]interface{}, isMain
 	if _, ok := action["time"]; !ok {
 		return Signature{}, errors.New("missing required field: time")
 	}
+	if err := validateActionAmount(action); err != nil {
+		return Signature{}, err
+	}
 
 	destination, ok := action["destination"].(string)
 	if ok && !common.IsHexAddress(destination) {
@@ -61,6 +91,12 @@ func SignWithdrawFromBridgeAction(wallet Wallet, action map[string]interface{},
 	if _, ok := action["time"]; !ok {
 		return Signature{}, errors.New("missing required field: time")
 	}
+	if err := validateActionAmount(action); err != nil {
+		return Signature{}, err
+	}
+	if err := validateWithdrawAmount(action["amount"].(string)); err != nil {
+		return Signature{}, err
+	}
 
 	// Validate destination address
 	destination, ok := action["destination"].(string)
@@ -71,6 +107,28 @@ func SignWithdrawFromBridgeAction(wallet Wallet, action map[string]interface{},
 	return SignUserSignedAction(wallet, action, WithdrawSignTypes, "HyperliquidTransaction:Withdraw", isMainnet)
 }
 
+// WithdrawPreview returns the amount received for a withdrawal of amount after the
+// flat WithdrawFeeUSD, and the fee itself
+func WithdrawPreview(amount float64) (net float64, fee float64) {
+	net = amount - WithdrawFeeUSD
+	if net < 0 {
+		net = 0
+	}
+	return net, WithdrawFeeUSD
+}
+
+// validateWithdrawAmount rejects withdrawals that would not cover the fee
+func validateWithdrawAmount(amount string) error {
+	value, err := SafeFloat64(amount)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if value <= WithdrawFeeUSD {
+		return fmt.Errorf("%w: withdrawal of %s does not exceed the %v USD fee", ErrInvalidAmount, amount, WithdrawFeeUSD)
+	}
+	return nil
+}
+
 func SignUSDClassTransferAction(wallet Wallet, action map[string]interface{}, isMainnet bool) (Signature, error) {
 	if _, ok := action["amount"]; !ok {
 		return Signature{}, errors.New("missing required field: amount")
@@ -81,6 +139,9 @@ func SignUSDClassTransferAction(wallet Wallet, action map[string]interface{}, is
 	if _, ok := action["nonce"]; !ok {
 		return Signature{}, errors.New("missing required field: nonce")
 	}
+	if err := validateActionAmount(action); err != nil {
+		return Signature{}, err
+	}
 
 	return SignUserSignedAction(wallet, action, USDClassTransferSignTypes, "HyperliquidTransaction:UsdClassTransfer", isMainnet)
 }
@@ -96,6 +157,27 @@ func SignConvertToMultiSigUserAction(wallet Wallet, action map[string]interface{
 	return SignUserSignedAction(wallet, action, ConvertToMultiSigUserSignTypes, "HyperliquidTransaction:ConvertToMultiSigUser", isMainnet)
 }
 
+// MaxAgentNameLength is the longest agent name ValidateAgentName accepts
+const MaxAgentNameLength = 64
+
+// ValidateAgentName checks an agent name is at most MaxAgentNameLength printable
+// ASCII characters without surrounding whitespace. The empty name, which approves
+// the default agent, is valid
+func ValidateAgentName(name string) error {
+	if len(name) > MaxAgentNameLength {
+		return fmt.Errorf("agent name is %d characters long, the maximum is %d", len(name), MaxAgentNameLength)
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c < 0x20 || c > 0x7e {
+			return fmt.Errorf("agent name %q: invalid character at offset %d", name, i)
+		}
+	}
+	if strings.TrimSpace(name) != name {
+		return fmt.Errorf("agent name %q has leading or trailing whitespace", name)
+	}
+	return nil
+}
+
 func SignAgentAction(wallet Wallet, action map[string]interface{}, isMainnet bool) (Signature, error) {
 	if _, ok := action["agentAddress"]; !ok {
 		return Signature{}, errors.New("missing required field: agentAddress")
@@ -113,7 +195,20 @@ func SignAgentAction(wallet Wallet, action map[string]interface{}, isMainnet boo
 		return Signature{}, fmt.Errorf("%w: agentAddress", ErrInvalidAddress)
 	}
 
-	return SignUserSignedAction(wallet, action, AgentSignTypes, "HyperliquidTransaction:ApproveAgent", isMainnet)
+	if agentName, ok := action["agentName"].(string); ok {
+		if err := ValidateAgentName(agentName); err != nil {
+			return Signature{}, err
+		}
+	}
+
+	// validUntil is only part of the typed data when set, so approvals without an
+	// expiry encode exactly as before
+	signTypes := AgentSignTypes
+	if _, ok := action["validUntil"]; ok {
+		signTypes = AgentWithExpirySignTypes
+	}
+
+	return SignUserSignedAction(wallet, action, signTypes, "HyperliquidTransaction:ApproveAgent", isMainnet)
 }
 
 func SignApproveBuilderFeeAction(wallet Wallet, action map[string]interface{}, isMainnet bool) (Signature, error) {
@@ -135,51 +230,221 @@ func SignApproveBuilderFeeAction(wallet Wallet, action map[string]interface{}, i
 	return SignUserSignedAction(wallet, action, BuilderFeeSignTypes, "HyperliquidTransaction:ApproveBuilderFee", isMainnet)
 }
 
-func CreateUSDTransferAction(destination string, amount string, timestamp uint64) map[string]interface{} {
-	return map[string]interface{}{
-		"destination": strings.ToLower(destination),
-		"amount":      amount,
-		"time":        timestamp,
+func SignCDepositAction(wallet Wallet, action map[string]interface{}, isMainnet bool) (Signature, error) {
+	if err := validateStakingFields(action); err != nil {
+		return Signature{}, err
 	}
+
+	return SignUserSignedAction(wallet, action, CDepositSignTypes, "HyperliquidTransaction:CDeposit", isMainnet)
 }
 
-func CreateSpotTransferAction(destination string, token string, amount string, timestamp uint64) map[string]interface{} {
-	return map[string]interface{}{
-		"destination": strings.ToLower(destination),
-		"token":       token,
-		"amount":      amount,
-		"time":        timestamp,
+func SignCWithdrawAction(wallet Wallet, action map[string]interface{}, isMainnet bool) (Signature, error) {
+	if err := validateStakingFields(action); err != nil {
+		return Signature{}, err
+	}
+
+	return SignUserSignedAction(wallet, action, CWithdrawSignTypes, "HyperliquidTransaction:CWithdraw", isMainnet)
+}
+
+func SignTokenDelegateAction(wallet Wallet, action map[string]interface{}, isMainnet bool) (Signature, error) {
+	if _, ok := action["validator"]; !ok {
+		return Signature{}, errors.New("missing required field: validator")
+	}
+	if _, ok := action["isUndelegate"]; !ok {
+		return Signature{}, errors.New("missing required field: isUndelegate")
+	}
+	if err := validateStakingFields(action); err != nil {
+		return Signature{}, err
+	}
+
+	return SignUserSignedAction(wallet, action, TokenDelegateSignTypes, "HyperliquidTransaction:TokenDelegate", isMainnet)
+}
+
+// validateStakingFields checks the wei and nonce fields shared by staking actions
+func validateStakingFields(action map[string]interface{}) error {
+	if _, ok := action["wei"]; !ok {
+		return errors.New("missing required field: wei")
+	}
+	if _, ok := action["nonce"]; !ok {
+		return errors.New("missing required field: nonce")
 	}
+	return nil
+}
+
+func CreateUSDTransferAction(destination string, amount string, timestamp uint64) map[string]interface{} {
+	return CreateUSDTransferActionStruct(destination, amount, timestamp).ToMap()
+}
+
+func CreateSpotTransferAction(destination string, token string, amount string, timestamp uint64) map[string]interface{} {
+	return CreateSpotTransferActionStruct(destination, token, amount, timestamp).ToMap()
 }
 
 func CreateWithdrawAction(destination string, amount string, timestamp uint64) map[string]interface{} {
-	return map[string]interface{}{
-		"destination": strings.ToLower(destination),
-		"amount":      amount,
-		"time":        timestamp,
+	return CreateWithdrawActionStruct(destination, amount, timestamp).ToMap()
+}
+
+// CreateWithdrawActionNet builds a withdraw action for grossAmount USD, which is
+// what leaves the account; destination receives grossAmount minus the returned
+// flat fee (see WithdrawFeeUSD). grossAmount must exceed the fee
+func CreateWithdrawActionNet(destination string, grossAmount float64, timestamp uint64) (map[string]interface{}, float64, error) {
+	amount, err := FloatToAmountString(grossAmount, USDDecimalPlaces)
+	if err != nil {
+		return nil, 0, fmt.Errorf("formatting withdraw amount: %w", err)
 	}
+	if err := validateWithdrawAmount(amount); err != nil {
+		return nil, 0, err
+	}
+
+	return CreateWithdrawAction(destination, amount, timestamp), WithdrawFeeUSD, nil
 }
 
 func CreateUSDClassTransferAction(amount string, toPerp bool, nonce uint64) map[string]interface{} {
-	return map[string]interface{}{
-		"amount": amount,
-		"toPerp": toPerp,
-		"nonce":  nonce,
-	}
+	return CreateUSDClassTransferActionStruct(amount, toPerp, nonce).ToMap()
 }
 
 func CreateAgentAction(agentAddress string, agentName string, nonce uint64) map[string]interface{} {
-	return map[string]interface{}{
-		"agentAddress": strings.ToLower(agentAddress),
-		"agentName":    agentName,
-		"nonce":        nonce,
-	}
+	return CreateAgentActionStruct(agentAddress, agentName, nonce).ToMap()
+}
+
+// CreateAgentActionWithExpiry is CreateAgentAction for an agent the exchange stops
+// accepting after validUntil (ms timestamp)
+func CreateAgentActionWithExpiry(agentAddress string, agentName string, nonce uint64, validUntil uint64) map[string]interface{} {
+	return CreateAgentActionWithExpiryStruct(agentAddress, agentName, nonce, validUntil).ToMap()
 }
 
 func CreateApproveBuilderFeeAction(maxFeeRate string, builder string, nonce uint64) map[string]interface{} {
+	return CreateApproveBuilderFeeActionStruct(maxFeeRate, builder, nonce).ToMap()
+}
+
+// CreateCDepositAction builds a cDeposit action moving amount HYPE from the spot
+// balance into staking. amount is in HYPE and converted with HypeToWei
+func CreateCDepositAction(amount float64, nonce uint64) (map[string]interface{}, error) {
+	action, err := CreateCDepositActionStruct(amount, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return action.ToMap(), nil
+}
+
+// CreateCWithdrawAction builds a cWithdraw action moving amount HYPE from staking
+// back to the spot balance. amount is in HYPE and converted with HypeToWei
+func CreateCWithdrawAction(amount float64, nonce uint64) (map[string]interface{}, error) {
+	action, err := CreateCWithdrawActionStruct(amount, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return action.ToMap(), nil
+}
+
+// CreateTokenDelegateAction builds a tokenDelegate action delegating amount HYPE
+// to validator, or undelegating it when isUndelegate is set. amount is in HYPE and
+// converted with HypeToWei
+func CreateTokenDelegateAction(validator string, amount float64, isUndelegate bool, nonce uint64) (map[string]interface{}, error) {
+	action, err := CreateTokenDelegateActionStruct(validator, amount, isUndelegate, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return action.ToMap(), nil
+}
+
+// BuildAndSignUSDTransfer builds a usdSend action whose time is also used as the
+// submission nonce, signs it and returns the complete /exchange payload
+func BuildAndSignUSDTransfer(wallet Wallet, destination, amount string, isMainnet bool) (map[string]interface{}, error) {
+	timestamp := uint64(GetTimestampMs())
+
+	action := CreateUSDTransferActionStruct(destination, amount, timestamp).ToMap()
+	action = PrepareUserSignedAction(action, isMainnet)
+
+	sig, err := SignUSDTransferAction(wallet, action, isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("signing usd transfer: %w", err)
+	}
+
+	return UserSignedActionPayload(action, timestamp, sig), nil
+}
+
+// BuildAndSignSpotTransfer builds a spotSend action whose time is also used as the
+// submission nonce, signs it and returns the complete /exchange payload
+func BuildAndSignSpotTransfer(wallet Wallet, destination, token, amount string, isMainnet bool) (map[string]interface{}, error) {
+	timestamp := uint64(GetTimestampMs())
+
+	action := CreateSpotTransferActionStruct(destination, token, amount, timestamp).ToMap()
+	action = PrepareUserSignedAction(action, isMainnet)
+
+	sig, err := SignSpotTransferAction(wallet, action, isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("signing spot transfer: %w", err)
+	}
+
+	return UserSignedActionPayload(action, timestamp, sig), nil
+}
+
+// BuildAndSignWithdraw builds a withdraw3 action whose time is also used as the
+// submission nonce, signs it and returns the complete /exchange payload
+func BuildAndSignWithdraw(wallet Wallet, destination, amount string, isMainnet bool) (map[string]interface{}, error) {
+	timestamp := uint64(GetTimestampMs())
+
+	action := CreateWithdrawActionStruct(destination, amount, timestamp).ToMap()
+	action = PrepareUserSignedAction(action, isMainnet)
+
+	sig, err := SignWithdrawFromBridgeAction(wallet, action, isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("signing withdraw: %w", err)
+	}
+
+	return UserSignedActionPayload(action, timestamp, sig), nil
+}
+
+// BuildAndSignUSDClassTransfer builds a usdClassTransfer action moving amount USD
+// between the spot and perp balances, whose nonce is also used as the submission
+// nonce, signs it and returns the complete /exchange payload
+func BuildAndSignUSDClassTransfer(wallet Wallet, amount string, toPerp bool, isMainnet bool) (map[string]interface{}, error) {
+	if err := ValidateAmountString(amount); err != nil {
+		return nil, err
+	}
+
+	nonce := uint64(GetTimestampMs())
+
+	action := CreateUSDClassTransferActionStruct(amount, toPerp, nonce).ToMap()
+	action = PrepareUserSignedAction(action, isMainnet)
+
+	sig, err := SignUSDClassTransferAction(wallet, action, isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("signing usd class transfer: %w", err)
+	}
+
+	return UserSignedActionPayload(action, nonce, sig), nil
+}
+
+// BuildAndSignApproveBuilderFee builds an approveBuilderFee action whose nonce is
+// also used as the submission nonce, signs it and returns the complete /exchange
+// payload. maxFeeRate must be a percent string such as "0.001%"
+func BuildAndSignApproveBuilderFee(wallet Wallet, maxFeeRate, builder string, isMainnet bool) (map[string]interface{}, error) {
+	if !strings.HasSuffix(maxFeeRate, "%") {
+		return nil, fmt.Errorf("maxFeeRate %q must be a percentage such as \"0.001%%\"", maxFeeRate)
+	}
+	if _, err := PercentToTenthsOfBps(maxFeeRate); err != nil {
+		return nil, fmt.Errorf("invalid maxFeeRate: %w", err)
+	}
+
+	nonce := uint64(GetTimestampMs())
+
+	action := CreateApproveBuilderFeeActionStruct(maxFeeRate, builder, nonce).ToMap()
+	action = PrepareUserSignedAction(action, isMainnet)
+
+	sig, err := SignApproveBuilderFeeAction(wallet, action, isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("signing approve builder fee: %w", err)
+	}
+
+	return UserSignedActionPayload(action, nonce, sig), nil
+}
+
+// UserSignedActionPayload assembles the /exchange request body of a user-signed action
+func UserSignedActionPayload(action map[string]interface{}, nonce uint64, sig Signature) map[string]interface{} {
 	return map[string]interface{}{
-		"maxFeeRate": maxFeeRate,
-		"builder":    strings.ToLower(builder),
-		"nonce":      nonce,
+		"action":    action,
+		"nonce":     nonce,
+		"signature": sig,
 	}
 }
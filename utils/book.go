@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Level is one price level of an order book: its price, total size and number of orders
+type Level struct {
+	Px float64
+	Sz float64
+	N  int
+}
+
+// L2Book is a level 2 order book snapshot. Levels[0] holds the bids, best first,
+// and Levels[1] the asks, best first
+type L2Book struct {
+	Coin   string
+	Time   int64
+	Levels [2][]Level
+}
+
+// BestBid returns the highest bid, if any
+func (b L2Book) BestBid() (Level, bool) {
+	if len(b.Levels[0]) == 0 {
+		return Level{}, false
+	}
+	return b.Levels[0][0], true
+}
+
+// BestAsk returns the lowest ask, if any
+func (b L2Book) BestAsk() (Level, bool) {
+	if len(b.Levels[1]) == 0 {
+		return Level{}, false
+	}
+	return b.Levels[1][0], true
+}
+
+// Mid returns the midpoint of the best bid and ask, if both sides are quoted
+func (b L2Book) Mid() (float64, bool) {
+	bid, ok := b.BestBid()
+	if !ok {
+		return 0, false
+	}
+	ask, ok := b.BestAsk()
+	if !ok {
+		return 0, false
+	}
+	return (bid.Px + ask.Px) / 2, true
+}
+
+// Microprice returns the best bid and ask weighted by the size on the opposite
+// side, which leans towards the side more likely to be hit. It is 0 unless both
+// sides are quoted
+func (b L2Book) Microprice() float64 {
+	bid, okBid := b.BestBid()
+	ask, okAsk := b.BestAsk()
+	if !okBid || !okAsk || bid.Sz+ask.Sz == 0 {
+		return 0
+	}
+	return (bid.Px*ask.Sz + ask.Px*bid.Sz) / (bid.Sz + ask.Sz)
+}
+
+// Spread returns the best ask minus the best bid. It is 0 unless both sides are quoted
+func (b L2Book) Spread() float64 {
+	bid, okBid := b.BestBid()
+	ask, okAsk := b.BestAsk()
+	if !okBid || !okAsk {
+		return 0
+	}
+	return ask.Px - bid.Px
+}
+
+// DepthWithin sums the bid and ask size priced within pct (a fraction, e.g. 0.01
+// for 1%) of the mid. Both are 0 unless both sides are quoted
+func (b L2Book) DepthWithin(pct float64) (bidSz, askSz float64) {
+	mid, ok := b.Mid()
+	if !ok {
+		return 0, 0
+	}
+
+	low, high := mid*(1-pct), mid*(1+pct)
+	for _, level := range b.Levels[0] {
+		if level.Px < low {
+			break
+		}
+		bidSz += level.Sz
+	}
+	for _, level := range b.Levels[1] {
+		if level.Px > high {
+			break
+		}
+		askSz += level.Sz
+	}
+	return bidSz, askSz
+}
+
+type rawLevel struct {
+	Px string `json:"px"`
+	Sz string `json:"sz"`
+	N  int    `json:"n"`
+}
+
+// ParseL2Book decodes an l2Book response, or the data of an l2Book subscription
+// message: {"coin": .., "time": .., "levels": [[bids..], [asks..]]}
+func ParseL2Book(data []byte) (L2Book, error) {
+	var raw struct {
+		Coin   string       `json:"coin"`
+		Time   int64        `json:"time"`
+		Levels [][]rawLevel `json:"levels"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return L2Book{}, fmt.Errorf("decoding l2 book: %w", err)
+	}
+	if len(raw.Levels) != 2 {
+		return L2Book{}, fmt.Errorf("l2 book for %s: expected 2 sides, got %d", raw.Coin, len(raw.Levels))
+	}
+
+	book := L2Book{Coin: raw.Coin, Time: raw.Time}
+	for side, rawLevels := range raw.Levels {
+		levels := make([]Level, len(rawLevels))
+		for i, r := range rawLevels {
+			px, err := SafeFloat64(r.Px)
+			if err != nil {
+				return L2Book{}, fmt.Errorf("l2 book for %s level %d px: %w", raw.Coin, i, err)
+			}
+			sz, err := SafeFloat64(r.Sz)
+			if err != nil {
+				return L2Book{}, fmt.Errorf("l2 book for %s level %d sz: %w", raw.Coin, i, err)
+			}
+			levels[i] = Level{Px: px, Sz: sz, N: r.N}
+		}
+		book.Levels[side] = levels
+	}
+
+	return book, nil
+}
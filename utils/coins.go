@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var coinNormalizationDisabled atomic.Bool
+
+// SetCoinNormalization turns the coin name normalization applied by asset lookups
+// on or off. It is enabled by default; when disabled, coins must match the asset
+// map exactly
+func SetCoinNormalization(enabled bool) {
+	coinNormalizationDisabled.Store(!enabled)
+}
+
+// NormalizeCoin returns the canonical ticker of a perp coin: trimmed, uppercased
+// and without a "-PERP" suffix, so "eth" and "ETH-PERP" both become "ETH". Spot
+// pair names ("PURR/USDC") and pair indexes ("@107") are returned trimmed only
+func NormalizeCoin(coin string) string {
+	coin = strings.TrimSpace(coin)
+	if strings.HasPrefix(coin, "@") || strings.Contains(coin, "/") {
+		return coin
+	}
+
+	coin = strings.ToUpper(coin)
+	return strings.TrimSuffix(coin, "-PERP")
+}
+
+// ParseSpotPairIndex parses the "@N" notation of spot pair N and returns its asset
+// index, SpotAssetOffset + N
+func ParseSpotPairIndex(coin string) (int, error) {
+	digits, ok := strings.CutPrefix(coin, "@")
+	if !ok {
+		return 0, fmt.Errorf("spot pair %q must be of the form @N", coin)
+	}
+
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 || digits[0] == '+' {
+		return 0, fmt.Errorf("spot pair %q must be of the form @N with N a non-negative integer", coin)
+	}
+
+	return SpotAssetOffset + n, nil
+}
+
+// LookupAsset resolves coin to its asset index. Spot pairs given as "@N" resolve
+// to SpotAssetOffset + N without consulting assetMap. An exact match always wins; unless
+// normalization is disabled, coin is then matched after NormalizeCoin and finally
+// case-insensitively, which finds mixed-case tickers such as "kPEPE"
+func LookupAsset(assetMap map[string]int, coin string) (int, bool) {
+	if strings.HasPrefix(coin, "@") {
+		asset, err := ParseSpotPairIndex(coin)
+		return asset, err == nil
+	}
+	if asset, ok := assetMap[coin]; ok {
+		return asset, true
+	}
+	if coinNormalizationDisabled.Load() {
+		return 0, false
+	}
+
+	normalized := NormalizeCoin(coin)
+	if asset, ok := assetMap[normalized]; ok {
+		return asset, true
+	}
+	for name, asset := range assetMap {
+		if strings.EqualFold(name, normalized) {
+			return asset, true
+		}
+	}
+	return 0, false
+}
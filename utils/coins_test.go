@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseSpotPairIndex(t *testing.T) {
+	for coin, want := range map[string]int{"@0": SpotAssetOffset, "@107": SpotAssetOffset + 107} {
+		got, err := ParseSpotPairIndex(coin)
+		if err != nil || got != want {
+			t.Errorf("ParseSpotPairIndex(%q) = %d, %v, want %d", coin, got, err, want)
+		}
+	}
+
+	for _, coin := range []string{"@abc", "@", "@-1", "@+1", "@1.5", "107", "PURR/USDC"} {
+		if got, err := ParseSpotPairIndex(coin); err == nil {
+			t.Errorf("ParseSpotPairIndex(%q) = %d, want error", coin, got)
+		}
+	}
+}
+
+func TestLookupAssetRejectsMalformedSpotPair(t *testing.T) {
+	// Even an asset map entry must not make "@abc" resolve
+	assetMap := map[string]int{"ETH": 4, "@abc": 7}
+
+	if asset, ok := LookupAsset(assetMap, "@abc"); ok {
+		t.Errorf("LookupAsset(@abc) = %d, want not found", asset)
+	}
+	if asset, ok := LookupAsset(assetMap, "@107"); !ok || asset != SpotAssetOffset+107 {
+		t.Errorf("LookupAsset(@107) = %d, %v", asset, ok)
+	}
+
+	orders := []OrderRequest{CreateLimitOrder("@abc", true, 1, 1, TIFGtc, false, nil)}
+	if _, err := BatchOrdersToWire(orders, assetMap); err == nil {
+		t.Error("BatchOrdersToWire accepted @abc")
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type countingLogger struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (l *countingLogger) Debug(string, ...interface{}) {
+	l.mu.Lock()
+	l.n++
+	l.mu.Unlock()
+}
+
+// TestConcurrentBatchSigning backs the package's concurrency claim: run it with
+// -race. Goroutines share one wallet, asset map and order slice while the signing
+// logger is swapped underneath them, and every signature must match the one
+// computed serially
+func TestConcurrentBatchSigning(t *testing.T) {
+	const (
+		goroutines = 16
+		iterations = 25
+	)
+
+	wallet := testWallet(t)
+	assetMap := map[string]int{"BTC": 0, "ETH": 4}
+	orders := []OrderRequest{
+		CreateLimitOrder("ETH", true, 0.0147, 1670.1, TIFIoc, false, nil),
+		CreateLimitOrder("BTC", false, 0.01, 60000, TIFGtc, true, nil),
+		CreateLimitOrder("@107", true, 12.5, 0.3112, TIFAlo, false, nil),
+	}
+
+	sign := func(nonce uint64) (Signature, error) {
+		wires, err := BatchOrdersToWire(orders, assetMap)
+		if err != nil {
+			return Signature{}, fmt.Errorf("BatchOrdersToWire: %w", err)
+		}
+		return SignOrderAction(wallet, OrderWiresToOrderAction(wires, ""), "", nonce, true)
+	}
+
+	want := make([]Signature, iterations)
+	for i := range want {
+		sig, err := sign(fixtureNonce + uint64(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[i] = sig
+	}
+
+	defer SetSigningLogger(nil)
+	stop := make(chan struct{})
+	var toggler sync.WaitGroup
+	toggler.Add(1)
+	go func() {
+		defer toggler.Done()
+		logger := &countingLogger{}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				SetSigningLogger(logger)
+			} else {
+				SetSigningLogger(nil)
+			}
+			LookupUserSignedActionType("usdSend")
+		}
+	}()
+
+	errs := make(chan error, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				sig, err := sign(fixtureNonce + uint64(i))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if sig != want[i] {
+					errs <- fmt.Errorf("nonce %d: signature %+v, want %+v", i, sig, want[i])
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	toggler.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if len(orders) != 3 || orders[0].Coin != "ETH" || orders[0].Cloid != nil {
+		t.Errorf("orders modified: %+v", orders)
+	}
+}
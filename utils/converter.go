@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	decimalCacheShards = 16
+
+	DefaultDecimalCacheSize     = 1000
+	DefaultDecimalCacheMaxValue = 1000000
+)
+
+// ConverterConfig configures a Converter
+type ConverterConfig struct {
+	// Places is the number of decimal places FloatToWire rounds to
+	Places int
+	// CacheSize bounds the number of cached decimals; 0 disables the cache
+	CacheSize int
+	// MaxCacheValue excludes values of larger magnitude from the cache
+	MaxCacheValue float64
+}
+
+func DefaultConverterConfig() ConverterConfig {
+	return ConverterConfig{
+		Places:        DefaultDecimalPlaces,
+		CacheSize:     DefaultDecimalCacheSize,
+		MaxCacheValue: DefaultDecimalCacheMaxValue,
+	}
+}
+
+// Converter converts floats to their decimal and wire representations with its
+// own precision policy and cache. It is safe for concurrent use
+type Converter struct {
+	places        int
+	maxCacheValue float64
+	shardSize     int
+
+	// cacheDisabled is inverted so that a configured cache starts enabled
+	cacheDisabled atomic.Bool
+	shards        [decimalCacheShards]decimalCacheShard
+}
+
+type decimalCacheKey struct {
+	value  float64
+	places int
+}
+
+// decimalCacheShard is one independently locked partition of a Converter cache
+type decimalCacheShard struct {
+	sync.RWMutex
+	values map[decimalCacheKey]decimal.Decimal
+}
+
+// defaultConverter backs the package-level conversion functions
+var defaultConverter = NewConverter(DefaultConverterConfig())
+
+// NewConverter creates a Converter. Its cache is sharded by value so concurrent
+// conversions rarely contend on the same lock; each shard holds at most
+// CacheSize/16 entries, bounding the whole cache to CacheSize
+func NewConverter(cfg ConverterConfig) *Converter {
+	c := &Converter{
+		places:        cfg.Places,
+		maxCacheValue: cfg.MaxCacheValue,
+		shardSize:     cfg.CacheSize / decimalCacheShards,
+	}
+	if c.shardSize == 0 {
+		c.cacheDisabled.Store(true)
+	}
+	for i := range c.shards {
+		c.shards[i].values = make(map[decimalCacheKey]decimal.Decimal, c.shardSize)
+	}
+	return c
+}
+
+// SetCacheEnabled turns the cache on or off. Enabling has no effect on a
+// Converter configured with a zero CacheSize
+func (c *Converter) SetCacheEnabled(enabled bool) {
+	c.cacheDisabled.Store(!enabled || c.shardSize == 0)
+}
+
+// Places returns the number of decimal places FloatToWire rounds to
+func (c *Converter) Places() int {
+	return c.places
+}
+
+// FloatToWire converts a float to a precise string representation
+func (c *Converter) FloatToWire(x float64) (string, error) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return "", fmt.Errorf("invalid float value: %v", x)
+	}
+
+	d, err := c.FloatToDecimal(x, c.places)
+	if err != nil {
+		return "", err
+	}
+
+	return d.String(), nil
+}
+
+// FloatsToWire converts every value with FloatToWire, returning a *WireError
+// identifying the first element that fails
+func (c *Converter) FloatsToWire(vals []float64) ([]string, error) {
+	result := make([]string, len(vals))
+	for i, v := range vals {
+		wire, err := c.FloatToWire(v)
+		if err != nil {
+			return nil, &WireError{Index: i, Value: v, Err: err}
+		}
+		result[i] = wire
+	}
+	return result, nil
+}
+
+// FloatToDecimal converts a float to a decimal.Decimal with the specified precision
+func (c *Converter) FloatToDecimal(x float64, places int) (decimal.Decimal, error) {
+	// Whole numbers need neither rounding nor the cache. Below 2^53 every integral
+	// float64 converts to int64 exactly
+	if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+		return decimal.NewFromInt(int64(x)), nil
+	}
+
+	useCache := !c.cacheDisabled.Load()
+	key := decimalCacheKey{value: x, places: places}
+
+	var shard *decimalCacheShard
+	if useCache {
+		shard = c.shardFor(x)
+		shard.RLock()
+		d, ok := shard.values[key]
+		shard.RUnlock()
+
+		if ok {
+			return d, nil
+		}
+	}
+
+	rounded := fmt.Sprintf("%.*f", places, x)
+	roundedFloat, err := strconv.ParseFloat(rounded, 64)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("parsing rounded float: %w", err)
+	}
+
+	if math.Abs(roundedFloat-x) >= PrecisionThreshold {
+		return decimal.Decimal{}, &PrecisionError{Original: x, Rounded: roundedFloat, Places: places}
+	}
+
+	if rounded[0] == '-' && roundedFloat == 0 {
+		rounded = rounded[1:]
+	}
+
+	d, err := decimal.NewFromString(rounded)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("creating decimal: %w", err)
+	}
+
+	if useCache && math.Abs(x) < c.maxCacheValue {
+		// The size check must happen under the write lock: reading len() under
+		// the read lock (or none) races with concurrent inserts and lets the
+		// shard grow past its bound
+		shard.Lock()
+		if len(shard.values) < c.shardSize {
+			shard.values[key] = d
+		}
+		shard.Unlock()
+	}
+
+	return d, nil
+}
+
+// shardFor returns the cache shard responsible for x
+func (c *Converter) shardFor(x float64) *decimalCacheShard {
+	bits := math.Float64bits(x)
+	bits ^= bits >> 33
+	bits *= 0xff51afd7ed558ccd
+	bits ^= bits >> 33
+	return &c.shards[bits%decimalCacheShards]
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// singleLockCache is the pre-sharding cache layout, one RWMutex over one map, kept
+// as a baseline for BenchmarkConverterCacheParallel
+type singleLockCache struct {
+	mu      sync.RWMutex
+	values  map[decimalCacheKey]decimal.Decimal
+	maxSize int
+	convert *Converter
+}
+
+func newSingleLockCache(size int) *singleLockCache {
+	return &singleLockCache{
+		values:  make(map[decimalCacheKey]decimal.Decimal, size),
+		maxSize: size,
+		convert: NewConverter(ConverterConfig{Places: DefaultDecimalPlaces}),
+	}
+}
+
+func (c *singleLockCache) FloatToDecimal(x float64, places int) (decimal.Decimal, error) {
+	key := decimalCacheKey{value: x, places: places}
+	c.mu.RLock()
+	d, ok := c.values[key]
+	c.mu.RUnlock()
+	if ok {
+		return d, nil
+	}
+
+	d, err := c.convert.FloatToDecimal(x, places)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	c.mu.Lock()
+	if len(c.values) < c.maxSize {
+		c.values[key] = d
+	}
+	c.mu.Unlock()
+	return d, nil
+}
+
+func TestConverterConcurrentCacheInserts(t *testing.T) {
+	const (
+		goroutines = 16
+		cacheSize  = 160
+	)
+
+	c := NewConverter(ConverterConfig{Places: DefaultDecimalPlaces, CacheSize: cacheSize, MaxCacheValue: DefaultDecimalCacheMaxValue})
+
+	// Far more distinct values than the cache holds, all inserted concurrently
+	values := make([]float64, 4000)
+	for i := range values {
+		values[i] = float64(i) + 0.5
+	}
+
+	errs := make(chan error, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := range values {
+				x := values[(i+g*251)%len(values)]
+				d, err := c.FloatToDecimal(x, DefaultDecimalPlaces)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if got, want := d.String(), fmt.Sprintf("%.1f", x); got != want {
+					errs <- fmt.Errorf("FloatToDecimal(%v) = %s, want %s", x, got, want)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	total := 0
+	for i := range c.shards {
+		n := len(c.shards[i].values)
+		if n > c.shardSize {
+			t.Errorf("shard %d holds %d entries, bound is %d", i, n, c.shardSize)
+		}
+		total += n
+	}
+	if total > cacheSize {
+		t.Errorf("cache holds %d entries, bound is %d", total, cacheSize)
+	}
+}
+
+// benchPrices are distinct fractional prices that all fit in the default cache
+func benchPrices() []float64 {
+	prices := make([]float64, 512)
+	for i := range prices {
+		prices[i] = 1000 + float64(i)*0.25 + 0.125
+	}
+	return prices
+}
+
+func BenchmarkConverterCacheParallel(b *testing.B) {
+	prices := benchPrices()
+	caches := []struct {
+		name    string
+		convert func(float64, int) (decimal.Decimal, error)
+	}{
+		{"sharded", NewConverter(DefaultConverterConfig()).FloatToDecimal},
+		{"single-lock", newSingleLockCache(DefaultDecimalCacheSize).FloatToDecimal},
+	}
+
+	for _, c := range caches {
+		b.Run(c.name, func(b *testing.B) {
+			var next atomic.Uint64
+			b.RunParallel(func(pb *testing.PB) {
+				i := int(next.Add(1)) * 97
+				for pb.Next() {
+					if _, err := c.convert(prices[i%len(prices)], DefaultDecimalPlaces); err != nil {
+						b.Fatal(err)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkFloatToDecimalIntegerFastPath(b *testing.B) {
+	inputs := map[string][]float64{
+		"integers":   {1, 10, 250, 60000, 123456, 1e9},
+		"fractional": {0.5, 1670.1, 0.0147, 60000.5, 123456.78, 0.3112},
+		"mixed":      {1, 1670.1, 250, 0.0147, 60000, 123456.78},
+	}
+
+	// Uncached shows the cost the fast path saves on a cache miss; cached compares
+	// it with a cache hit
+	configs := []struct {
+		name string
+		cfg  ConverterConfig
+	}{
+		{"uncached", ConverterConfig{Places: DefaultDecimalPlaces}},
+		{"cached", DefaultConverterConfig()},
+	}
+
+	for _, cfg := range configs {
+		for _, name := range []string{"integers", "fractional", "mixed"} {
+			vals := inputs[name]
+			b.Run(cfg.name+"/"+name, func(b *testing.B) {
+				c := NewConverter(cfg.cfg)
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := c.FloatToDecimal(vals[i%len(vals)], DefaultDecimalPlaces); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
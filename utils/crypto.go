@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -14,18 +15,120 @@ import (
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// AddressToBytes returns the 20-byte form of address, which may be any casing
+// and with or without the 0x prefix
 func AddressToBytes(address string) ([]byte, error) {
 	if !common.IsHexAddress(address) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, address)
 	}
 
-	address = strings.TrimPrefix(address, "0x")
-	return hex.DecodeString(address)
+	return common.HexToAddress(address).Bytes(), nil
+}
+
+// sameAddress reports whether a and b are valid addresses with the same 20 bytes
+func sameAddress(a, b string) bool {
+	aBytes, err := AddressToBytes(a)
+	if err != nil {
+		return false
+	}
+	bBytes, err := AddressToBytes(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
+
+// BytesToAddress returns the EIP-55 checksummed string of a 20-byte address
+func BytesToAddress(b []byte) (string, error) {
+	if len(b) != common.AddressLength {
+		return "", fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidAddress, common.AddressLength, len(b))
+	}
+
+	return common.BytesToAddress(b).Hex(), nil
+}
+
+// ValidateChecksumAddress checks addr is a hex address and, when it is mixed-case,
+// that its casing matches the EIP-55 checksum. All-lowercase and all-uppercase
+// addresses carry no checksum and pass
+func ValidateChecksumAddress(addr string) error {
+	if !common.IsHexAddress(addr) {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, addr)
+	}
+
+	hexPart := strings.TrimPrefix(strings.TrimPrefix(addr, "0x"), "0X")
+	if hexPart == strings.ToLower(hexPart) || hexPart == strings.ToUpper(hexPart) {
+		return nil
+	}
+
+	if expected := common.HexToAddress(addr).Hex(); "0x"+hexPart != expected {
+		return fmt.Errorf("%w: %s (expected %s)", ErrInvalidChecksum, addr, expected)
+	}
+	return nil
 }
 
 // ActionHash calculates the hash of an action for signing purposes
 func ActionHash(action interface{}, vaultAddress string, nonce uint64) ([]byte, error) {
-	data, err := msgpack.Marshal(action)
+	return ActionHashWithExpiry(action, vaultAddress, nonce, nil)
+}
+
+// ActionHashWithExpiry calculates the hash of an action that carries an optional
+// expiresAfter timestamp; when set it is appended after the vault marker
+func ActionHashWithExpiry(action interface{}, vaultAddress string, nonce uint64, expiresAfter *uint64) ([]byte, error) {
+	data, err := actionHashPayload(action, vaultAddress, nonce, expiresAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := crypto.Keccak256(data)
+	return hash, nil
+}
+
+// ActionHashDebug computes the same hash as ActionHash and also returns the hex
+// encoding of the full pre-hash buffer (msgpack action, nonce and vault marker),
+// which can be diffed byte for byte against the reference Python SDK
+func ActionHashDebug(action interface{}, vaultAddress string, nonce uint64) ([]byte, string, error) {
+	data, err := actionHashPayload(action, vaultAddress, nonce, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return crypto.Keccak256(data), hexutil.Encode(data), nil
+}
+
+// MarshalAction encodes action to the exact msgpack bytes ActionHash hashes; see
+// canonicalMsgpackMarshal for the ordering it guarantees
+func MarshalAction(action interface{}) ([]byte, error) {
+	return canonicalMsgpackMarshal(action)
+}
+
+// canonicalMsgpackMarshal encodes v the way the reference Python SDK packs actions.
+// Python packs dict keys in insertion order, which Go maps cannot express, so the
+// ordering contract is:
+//   - struct fields are packed in declaration order; every L1 action this package
+//     builds, including the multiSig action SignMultiSigAction hashes, is a struct
+//     whose fields are declared in the SDK's insertion order
+//   - SimpleL1Action packs its type first and its fields sorted
+//   - any other map has its keys sorted, which matches the SDK only when its
+//     insertion order happens to be alphabetical, so caller-built maps should not
+//     be hashed as L1 actions
+//
+// Integers use their most compact representation, as Python's msgpack does
+func canonicalMsgpackMarshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// actionHashPayload builds the byte buffer that ActionHash hashes
+func actionHashPayload(action interface{}, vaultAddress string, nonce uint64, expiresAfter *uint64) ([]byte, error) {
+	data, err := canonicalMsgpackMarshal(action)
 	if err != nil {
 		return nil, fmt.Errorf("marshalling action: %w", err)
 	}
@@ -45,8 +148,45 @@ func ActionHash(action interface{}, vaultAddress string, nonce uint64) ([]byte,
 		data = append(data, addrBytes...)
 	}
 
-	hash := crypto.Keccak256(data)
-	return hash, nil
+	if expiresAfter != nil {
+		expiryBytes := make([]byte, 8)
+		binary.BigEndian.PutUint64(expiryBytes, *expiresAfter)
+		data = append(data, 0)
+		data = append(data, expiryBytes...)
+	}
+
+	return data, nil
+}
+
+// ActionConnectionID returns the 0x-hex action hash that SignL1Action places in the
+// phantom agent's connectionId
+func ActionConnectionID(action interface{}, vaultAddress string, nonce uint64) (string, error) {
+	hash, err := ActionHash(action, vaultAddress, nonce)
+	if err != nil {
+		return "", err
+	}
+
+	return hexutil.Encode(hash), nil
+}
+
+// VerifyActionHash recomputes the action hash and compares it with expectedHex, a
+// 0x-hex hash such as a logged connectionId. On mismatch it returns false together
+// with an error showing both hashes
+func VerifyActionHash(action interface{}, vaultAddress string, nonce uint64, expectedHex string) (bool, error) {
+	expected, err := hexutil.Decode(expectedHex)
+	if err != nil {
+		return false, fmt.Errorf("decoding expected hash %q: %w", expectedHex, err)
+	}
+
+	hash, err := ActionHash(action, vaultAddress, nonce)
+	if err != nil {
+		return false, err
+	}
+
+	if !bytes.Equal(hash, expected) {
+		return false, fmt.Errorf("action hash mismatch:\n  expected %s\n  computed %s", hexutil.Encode(expected), hexutil.Encode(hash))
+	}
+	return true, nil
 }
 
 // ConstructPhantomAgent constructs a phantom agent data structure
@@ -56,10 +196,22 @@ func ConstructPhantomAgent(hash []byte, isMainnet bool) map[string]interface{} {
 		source = "b" // testnet
 	}
 
+	// Both sources are single characters, so this cannot fail
+	agent, _ := ConstructPhantomAgentSource(hash, source)
+	return agent
+}
+
+// ConstructPhantomAgentSource constructs a phantom agent for an explicit source,
+// which must be a single character ("a" on mainnet, "b" on testnet)
+func ConstructPhantomAgentSource(hash []byte, source string) (map[string]interface{}, error) {
+	if utf8.RuneCountInString(source) != 1 {
+		return nil, fmt.Errorf("phantom agent source must be a single character, got %q", source)
+	}
+
 	return map[string]interface{}{
 		"source":       source,
 		"connectionId": hexutil.Encode(hash),
-	}
+	}, nil
 }
 
 // HashMessage computes the Ethereum signed message hash
@@ -93,7 +245,13 @@ func VerifySignature(address string, message []byte, sig Signature) (bool, error
 
 	hash := HashMessage(message)
 
-	pubKey, err := crypto.Ecrecover(hash, append(append(r, s...), sig.V))
+	// Accept both the raw recovery ID and the 27/28 form used by the exchange
+	v := sig.V
+	if v >= 27 {
+		v -= 27
+	}
+
+	pubKey, err := crypto.Ecrecover(hash, append(append(r, s...), v))
 	if err != nil {
 		return false, fmt.Errorf("recovering public key: %w", err)
 	}
@@ -114,5 +272,5 @@ func GenerateRandomCloid() (Cloid, error) {
 		return "", fmt.Errorf("generating random bytes: %w", err)
 	}
 
-	return Cloid(hex.EncodeToString(randBytes)), nil
+	return Cloid("0x" + hex.EncodeToString(randBytes)), nil
 }
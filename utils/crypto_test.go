@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const fixtureNonce uint64 = 1677777606040
+
+func fixtureCancelAction() CancelAction {
+	return CancelAction{Type: "cancel", Cancels: []CancelWire{{Asset: 4, OrderID: 123456789}}}
+}
+
+func TestActionHashWithExpiry(t *testing.T) {
+	action := fixtureCancelAction()
+	expiresAfter := fixtureNonce + 60000
+
+	plain, err := ActionHash(action, "", fixtureNonce)
+	if err != nil {
+		t.Fatalf("ActionHash: %v", err)
+	}
+
+	withNil, err := ActionHashWithExpiry(action, "", fixtureNonce, nil)
+	if err != nil {
+		t.Fatalf("ActionHashWithExpiry(nil): %v", err)
+	}
+	if !bytes.Equal(plain, withNil) {
+		t.Errorf("nil expiresAfter changed the hash: %x != %x", withNil, plain)
+	}
+
+	withExpiry, err := ActionHashWithExpiry(action, "", fixtureNonce, &expiresAfter)
+	if err != nil {
+		t.Fatalf("ActionHashWithExpiry: %v", err)
+	}
+	if bytes.Equal(plain, withExpiry) {
+		t.Errorf("expiresAfter did not change the hash %x", plain)
+	}
+
+	later := expiresAfter + 1
+	withLater, err := ActionHashWithExpiry(action, "", fixtureNonce, &later)
+	if err != nil {
+		t.Fatalf("ActionHashWithExpiry: %v", err)
+	}
+	if bytes.Equal(withExpiry, withLater) {
+		t.Errorf("different expiresAfter values produced the same hash %x", withExpiry)
+	}
+}
+
+func TestActionHashPayloadExpiryLayout(t *testing.T) {
+	action := fixtureCancelAction()
+	expiresAfter := fixtureNonce + 60000
+
+	base, err := actionHashPayload(action, "", fixtureNonce, nil)
+	if err != nil {
+		t.Fatalf("actionHashPayload: %v", err)
+	}
+
+	withExpiry, err := actionHashPayload(action, "", fixtureNonce, &expiresAfter)
+	if err != nil {
+		t.Fatalf("actionHashPayload: %v", err)
+	}
+
+	// The expiry follows the vault marker as a zero byte and 8 big-endian bytes
+	want := append(append([]byte{}, base...), 0)
+	want = binary.BigEndian.AppendUint64(want, expiresAfter)
+	if !bytes.Equal(withExpiry, want) {
+		t.Errorf("payload with expiry\n got %x\nwant %x", withExpiry, want)
+	}
+}
+
+func TestActionHashPayloadVaultAndExpiryFixture(t *testing.T) {
+	// Reference bytes for the SDK's action_hash(cancel, vault, nonce, expires_after)
+	const want = "0x82a474797065a663616e63656ca763616e63656c739182a16104a16fce075bcd1500000186a3569598011719884eb866cb12b2287399b15f7db5e7d775ea0000000186a3577ff8"
+
+	expiresAfter := fixtureNonce + 60000
+	got, err := actionHashPayload(fixtureCancelAction(), "0x1719884eb866cb12b2287399b15f7db5e7d775ea", fixtureNonce, &expiresAfter)
+	if err != nil {
+		t.Fatalf("actionHashPayload: %v", err)
+	}
+	if hexutil.Encode(got) != want {
+		t.Errorf("payload\n got %s\nwant %s", hexutil.Encode(got), want)
+	}
+}
+
+func TestMarshalActionFixtures(t *testing.T) {
+	tests := []struct {
+		name   string
+		action interface{}
+		want   string
+	}{
+		{
+			name:   "cancel struct in declaration order",
+			action: fixtureCancelAction(),
+			want:   "0x82a474797065a663616e63656ca763616e63656c739182a16104a16fce075bcd15",
+		},
+		{
+			name:   "map keys sorted",
+			action: map[string]interface{}{"type": "setDisplayName", "displayName": "alice"},
+			want:   "0x82ab646973706c61794e616d65a5616c696365a474797065ae736574446973706c61794e616d65",
+		},
+		{
+			name:   "compact integers",
+			action: map[string]interface{}{"a": uint64(300), "b": int64(1), "c": -1, "d": uint64(fixtureNonce)},
+			want:   "0x84a161cd012ca16201a163ffa164cf00000186a3569598",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalAction(tt.action)
+			if err != nil {
+				t.Fatalf("MarshalAction: %v", err)
+			}
+			if hexutil.Encode(got) != tt.want {
+				t.Errorf("bytes\n got %s\nwant %s", hexutil.Encode(got), tt.want)
+			}
+		})
+	}
+}
+
+// TestActionHashPayloadGolden pins the payload ActionHash hashes against bytes the
+// reference SDK's action_hash builds at fixtureNonce with no vault
+func TestActionHashPayloadGolden(t *testing.T) {
+	cloid := Cloid("0x00000000000000000000000000000001")
+	assetMap := map[string]int{"BTC": 0, "ETH": 4}
+
+	orderAction := func(order OrderRequest, grouping GroupingType) OrderAction {
+		t.Helper()
+		wires, err := BatchOrdersToWire([]OrderRequest{order}, assetMap)
+		if err != nil {
+			t.Fatalf("BatchOrdersToWire: %v", err)
+		}
+		action, err := OrderWiresToOrderActionGrouped(wires, grouping, "")
+		if err != nil {
+			t.Fatalf("OrderWiresToOrderActionGrouped: %v", err)
+		}
+		return action
+	}
+
+	cancelByCloid, err := CreateCancelByCloidAction([]CancelByCloidRequest{{Coin: "ETH", Cloid: cloid}}, assetMap)
+	if err != nil {
+		t.Fatalf("CreateCancelByCloidAction: %v", err)
+	}
+	updateLeverage, err := CreateUpdateLeverageAction(4, true, 10)
+	if err != nil {
+		t.Fatalf("CreateUpdateLeverageAction: %v", err)
+	}
+	trigger, err := CreateTriggerOrder("BTC", false, 0.01, 60000, 61000.5, true, TPSLStopLoss, true, nil)
+	if err != nil {
+		t.Fatalf("CreateTriggerOrder: %v", err)
+	}
+	multiSig := MultiSigAction{
+		SignatureChainID: "0x66eee",
+		Signatures: []Signature{{
+			R: "0x1111111111111111111111111111111111111111111111111111111111111111",
+			S: "0x2222222222222222222222222222222222222222222222222222222222222222",
+			V: 27,
+		}},
+		Payload: MultiSigPayload{
+			MultiSigUser: "0x1719884eb866cb12b2287399b15f7db5e7d775ea",
+			OuterSigner:  "0x14791697260e4c9a71f18484c9f997b308e59325",
+			Action:       fixtureCancelAction(),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		action interface{}
+		want   string
+	}{
+		{
+			name:   "order",
+			action: orderAction(CreateLimitOrder("ETH", true, 0.0147, 1670.1, TIFIoc, false, nil), GroupingNA),
+			want:   "0x83a474797065a56f72646572a66f72646572739186a16104a162c3a170a6313637302e31a173a6302e30313437a172c2a17481a56c696d697481a3746966a3496f63a867726f7570696e67a26e6100000186a356959800",
+		},
+		{
+			name:   "order with cloid",
+			action: orderAction(CreateLimitOrder("ETH", true, 0.0147, 1670.1, TIFIoc, false, &cloid), GroupingNA),
+			want:   "0x83a474797065a56f72646572a66f72646572739187a16104a162c3a170a6313637302e31a173a6302e30313437a172c2a17481a56c696d697481a3746966a3496f63a163d92230783030303030303030303030303030303030303030303030303030303030303031a867726f7570696e67a26e6100000186a356959800",
+		},
+		{
+			name:   "trigger order",
+			action: orderAction(trigger, GroupingPositionTPSL),
+			want:   "0x83a474797065a56f72646572a66f72646572739186a16100a162c2a170a53630303030a173a4302e3031a172c3a17481a77472696767657283a869734d61726b6574c3a9747269676765725078a736313030302e35a47470736ca2736ca867726f7570696e67ac706f736974696f6e5470736c00000186a356959800",
+		},
+		{
+			name:   "cancel",
+			action: fixtureCancelAction(),
+			want:   "0x82a474797065a663616e63656ca763616e63656c739182a16104a16fce075bcd1500000186a356959800",
+		},
+		{
+			name:   "cancelByCloid",
+			action: cancelByCloid,
+			want:   "0x82a474797065ad63616e63656c4279436c6f6964a763616e63656c739182a5617373657404a5636c6f6964d9223078303030303030303030303030303030303030303030303030303030303030303100000186a356959800",
+		},
+		{
+			name:   "updateLeverage",
+			action: updateLeverage,
+			want:   "0x84a474797065ae7570646174654c65766572616765a5617373657404a7697343726f7373c3a86c657665726167650a00000186a356959800",
+		},
+		{
+			name:   "SimpleL1Action",
+			action: CreateSetDisplayNameAction("alice"),
+			want:   "0x82a474797065ae736574446973706c61794e616d65ab646973706c61794e616d65a5616c69636500000186a356959800",
+		},
+		{
+			name:   "multiSig without type",
+			action: multiSig,
+			want:   "0x83b07369676e6174757265436861696e4964a730783636656565aa7369676e6174757265739183a172d942307831313131313131313131313131313131313131313131313131313131313131313131313131313131313131313131313131313131313131313131313131313131a173d942307832323232323232323232323232323232323232323232323232323232323232323232323232323232323232323232323232323232323232323232323232323232a1761ba77061796c6f616483ac6d756c746953696755736572d92a307831373139383834656238363663623132623232383733393962313566376462356537643737356561ab6f757465725369676e6572d92a307831343739313639373236306534633961373166313834383463396639393762333038653539333235a6616374696f6e82a474797065a663616e63656ca763616e63656c739182a16104a16fce075bcd1500000186a356959800",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := actionHashPayload(tt.action, "", fixtureNonce, nil)
+			if err != nil {
+				t.Fatalf("actionHashPayload: %v", err)
+			}
+			if hexutil.Encode(payload) != tt.want {
+				t.Errorf("payload\n got %s\nwant %s", hexutil.Encode(payload), tt.want)
+			}
+
+			hash, err := ActionHash(tt.action, "", fixtureNonce)
+			if err != nil {
+				t.Fatalf("ActionHash: %v", err)
+			}
+			if want := crypto.Keccak256(hexutil.MustDecode(tt.want)); !bytes.Equal(hash, want) {
+				t.Errorf("hash = %x, want %x", hash, want)
+			}
+		})
+	}
+}
@@ -4,24 +4,28 @@ import (
 	"fmt"
 	"math"
 	"strconv"
-	"sync"
 
 	"github.com/shopspring/decimal"
 )
 
-// decimalCache provides a cache for common decimal values to reduce allocations
-var decimalCache = struct {
-	sync.RWMutex
-	values map[float64]decimal.Decimal
-}{values: make(map[float64]decimal.Decimal, 100)}
-
-// FloatToWire converts a float to a precise string representation
+// FloatToWire converts a float to a precise string representation using the
+// default Converter
 func FloatToWire(x float64) (string, error) {
+	return defaultConverter.FloatToWire(x)
+}
+
+// FloatToAmountString formats a transfer amount as the plain decimal string the
+// transfer actions expect: no exponent, no trailing zeros, and at most decimals
+// decimal places (a *PrecisionError is returned otherwise)
+func FloatToAmountString(x float64, decimals int) (string, error) {
 	if math.IsNaN(x) || math.IsInf(x, 0) {
 		return "", fmt.Errorf("invalid float value: %v", x)
 	}
+	if x < 0 {
+		return "", fmt.Errorf("%w: %v is negative", ErrInvalidAmount, x)
+	}
 
-	d, err := FloatToDecimal(x, DefaultDecimalPlaces)
+	d, err := FloatToDecimal(x, decimals)
 	if err != nil {
 		return "", err
 	}
@@ -29,42 +33,67 @@ func FloatToWire(x float64) (string, error) {
 	return d.String(), nil
 }
 
-// FloatToDecimal converts a float to a decimal.Decimal with the specified precision
-func FloatToDecimal(x float64, places int) (decimal.Decimal, error) {
-	decimalCache.RLock()
-	d, ok := decimalCache.values[x]
-	decimalCache.RUnlock()
+// PrecisionError is returned when a value cannot be represented at the requested
+// number of decimal places. Rounded is the closest value that can, so callers can
+// retry with it. It matches ErrPrecisionLoss with errors.Is
+type PrecisionError struct {
+	Original float64
+	Rounded  float64
+	Places   int
+}
 
-	if ok {
-		return d, nil
-	}
+func (e *PrecisionError) Error() string {
+	return fmt.Sprintf("%v: %v has more than %d decimal places (rounded: %v)",
+		ErrPrecisionLoss, e.Original, e.Places, e.Rounded)
+}
 
-	rounded := fmt.Sprintf("%.*f", places, x)
-	roundedFloat, err := strconv.ParseFloat(rounded, 64)
-	if err != nil {
-		return decimal.Decimal{}, fmt.Errorf("parsing rounded float: %w", err)
-	}
+func (e *PrecisionError) Unwrap() error {
+	return ErrPrecisionLoss
+}
 
-	if math.Abs(roundedFloat-x) >= PrecisionThreshold {
-		return decimal.Decimal{}, fmt.Errorf("%w: %.12f vs %.12f", ErrPrecisionLoss, x, roundedFloat)
-	}
+// WireError reports a value that could not be converted to its wire representation.
+// Index is the position within a batch, or -1 when the value is not part of one
+type WireError struct {
+	Index int
+	Field string
+	Value float64
+	Err   error
+}
 
-	if rounded[0] == '-' && roundedFloat == 0 {
-		rounded = rounded[1:]
+func (e *WireError) Error() string {
+	var name string
+	switch {
+	case e.Index >= 0 && e.Field != "":
+		name = fmt.Sprintf("order[%d] %s", e.Index, e.Field)
+	case e.Index >= 0:
+		name = fmt.Sprintf("value[%d]", e.Index)
+	default:
+		name = e.Field
 	}
+	return fmt.Sprintf("%s %v: %v", name, e.Value, e.Err)
+}
 
-	d, err = decimal.NewFromString(rounded)
-	if err != nil {
-		return decimal.Decimal{}, fmt.Errorf("creating decimal: %w", err)
-	}
+func (e *WireError) Unwrap() error {
+	return e.Err
+}
 
-	if len(decimalCache.values) < 1000 && math.Abs(x) < 1000000 {
-		decimalCache.Lock()
-		decimalCache.values[x] = d
-		decimalCache.Unlock()
-	}
+// FloatsToWire converts every value with FloatToWire, returning a *WireError
+// identifying the first element that fails
+func FloatsToWire(vals []float64) ([]string, error) {
+	return defaultConverter.FloatsToWire(vals)
+}
 
-	return d, nil
+// FloatToDecimal converts a float to a decimal.Decimal with the specified precision
+// using the default Converter
+func FloatToDecimal(x float64, places int) (decimal.Decimal, error) {
+	return defaultConverter.FloatToDecimal(x, places)
+}
+
+// SetDecimalCacheEnabled turns the default Converter's cache on or off. The cache
+// is enabled by default; disabling it skips every cache lock, which pays off for
+// workloads that rarely convert the same value twice
+func SetDecimalCacheEnabled(enabled bool) {
+	defaultConverter.SetCacheEnabled(enabled)
 }
 
 // FloatToIntForHashing converts a float to an integer with 8 decimal places
@@ -77,26 +106,35 @@ func FloatToUSDInt(x float64) (int64, error) {
 	return FloatToInt(x, USDDecimalPlaces)
 }
 
-// FloatToInt converts a float to an integer with specified decimal places
+// HypeToWei converts a HYPE amount to the 8-decimal wei units staking actions
+// carry. Negative amounts and amounts finer than 1e-8 HYPE are rejected
+func HypeToWei(amount float64) (int64, error) {
+	if amount < 0 {
+		return 0, fmt.Errorf("%w: negative HYPE amount %v", ErrInvalidAmount, amount)
+	}
+	return FloatToInt(amount, HypeDecimalPlaces)
+}
+
+// FloatToInt converts a float to an integer with specified decimal places. The
+// shift is done on the shortest decimal representation of x so that values like
+// 0.1 are scaled exactly
 func FloatToInt(x float64, places int) (int64, error) {
 	if math.IsNaN(x) || math.IsInf(x, 0) {
 		return 0, fmt.Errorf("invalid float value: %v", x)
 	}
 
-	scale := math.Pow10(places)
-	withDecimals := x * scale
-
-	if math.Abs(math.Round(withDecimals)-withDecimals) >= 1e-3 {
-		return 0, fmt.Errorf("%w: %v would lose precision at %d decimal places",
-			ErrPrecisionLoss, x, places)
+	shifted := decimal.NewFromFloat(x).Shift(int32(places))
+	if !shifted.IsInteger() {
+		rounded := shifted.Round(0).Shift(-int32(places)).InexactFloat64()
+		return 0, &PrecisionError{Original: x, Rounded: rounded, Places: places}
 	}
 
-	result := int64(math.Round(withDecimals))
-	if float64(result) != math.Round(withDecimals) {
-		return 0, fmt.Errorf("integer overflow converting %v to int64", withDecimals)
+	result := shifted.BigInt()
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("integer overflow converting %v to int64", shifted)
 	}
 
-	return result, nil
+	return result.Int64(), nil
 }
 
 // SafeFloat64 attempts to convert a string to a float64 with error handling
@@ -113,6 +151,25 @@ func SafeFloat64(s string) (float64, error) {
 	return f, nil
 }
 
+// SafeFloat64InRange parses s like SafeFloat64 and additionally requires the result
+// to be finite and within [min, max]
+func SafeFloat64InRange(s string, min, max float64) (float64, error) {
+	f, err := SafeFloat64(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("invalid float value: %v", f)
+	}
+
+	if f < min || f > max {
+		return 0, fmt.Errorf("value %v out of range [%v, %v]", f, min, max)
+	}
+
+	return f, nil
+}
+
 // DecimalToFloat64 safely converts a decimal.Decimal to float64, reporting errors
 // if precision loss would occur beyond the specified tolerance
 func DecimalToFloat64(d decimal.Decimal) (float64, error) {
@@ -135,3 +192,16 @@ func RoundFloat64(x float64, places int) float64 {
 	scale := math.Pow10(places)
 	return math.Round(x*scale) / scale
 }
+
+// RoundFloat64Checked is RoundFloat64 that also reports whether rounding changed x
+func RoundFloat64Checked(x float64, places int) (rounded float64, changed bool) {
+	rounded = RoundFloat64(x, places)
+	return rounded, rounded != x
+}
+
+// RoundDecimalChecked rounds d half away from zero to places decimal places and
+// reports whether rounding changed it
+func RoundDecimalChecked(d decimal.Decimal, places int) (rounded decimal.Decimal, changed bool) {
+	rounded = d.Round(int32(places))
+	return rounded, !rounded.Equal(d)
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFloatToIntExact(t *testing.T) {
+	tests := []struct {
+		x      float64
+		places int
+		want   int64
+	}{
+		{0.07, 6, 70000},
+		{0.07, 8, 7000000},
+		{1.1, 6, 1100000},
+		{1.1, 8, 110000000},
+		{123456.78, 6, 123456780000},
+		{123456.78, 8, 12345678000000},
+		{0.1, 8, 10000000},
+		{-1.1, 8, -110000000},
+		{0, 8, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := FloatToInt(tt.x, tt.places)
+		if err != nil {
+			t.Errorf("FloatToInt(%v, %d): %v", tt.x, tt.places, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FloatToInt(%v, %d) = %d, want %d", tt.x, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestFloatToIntPrecisionLoss(t *testing.T) {
+	_, err := FloatToInt(0.1234567, 6)
+
+	var precisionErr *PrecisionError
+	if !errors.As(err, &precisionErr) {
+		t.Fatalf("FloatToInt(0.1234567, 6) error = %v, want *PrecisionError", err)
+	}
+	if !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("error %v does not match ErrPrecisionLoss", err)
+	}
+	if precisionErr.Rounded != 0.123457 {
+		t.Errorf("Rounded = %v, want 0.123457", precisionErr.Rounded)
+	}
+}
+
+func TestFloatToIntOverflow(t *testing.T) {
+	if got, err := FloatToInt(1e12, 8); err == nil {
+		t.Errorf("FloatToInt(1e12, 8) = %d, want an overflow error", got)
+	}
+}
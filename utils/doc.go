@@ -0,0 +1,7 @@
+// Package utils builds, encodes and signs Hyperliquid actions.
+//
+// All package-level functions are safe for concurrent use. The shared state they
+// rely on (the default Converter's cache, the user-signed action registry and the
+// signing logger) is synchronized internally; actions passed to the signing
+// functions are only read, never modified.
+package utils
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EIP1271MagicValue is returned by isValidSignature(bytes32,bytes) for a valid signature
+var EIP1271MagicValue = []byte{0x16, 0x26, 0xba, 0x7e}
+
+var ErrNoContractCode = errors.New("no contract code at address")
+
+var isValidSignatureSelector = crypto.Keccak256([]byte("isValidSignature(bytes32,bytes)"))[:4]
+
+// VerifySignatureEIP1271 verifies a smart-contract wallet signature by calling
+// isValidSignature on contract with the same message hash VerifySignature checks
+func VerifySignatureEIP1271(ctx context.Context, ethClient bind.ContractCaller, contract common.Address, message []byte, sig []byte) (bool, error) {
+	code, err := ethClient.CodeAt(ctx, contract, nil)
+	if err != nil {
+		return false, fmt.Errorf("fetching contract code: %w", err)
+	}
+	if len(code) == 0 {
+		return false, fmt.Errorf("%w: %s", ErrNoContractCode, contract.Hex())
+	}
+
+	bytes32Type, err := abi.NewType("bytes32", "", nil)
+	if err != nil {
+		return false, fmt.Errorf("creating abi type: %w", err)
+	}
+	bytesType, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return false, fmt.Errorf("creating abi type: %w", err)
+	}
+
+	var hash [32]byte
+	copy(hash[:], HashMessage(message))
+
+	args, err := abi.Arguments{{Type: bytes32Type}, {Type: bytesType}}.Pack(hash, sig)
+	if err != nil {
+		return false, fmt.Errorf("packing isValidSignature call: %w", err)
+	}
+
+	result, err := ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &contract,
+		Data: append(append([]byte{}, isValidSignatureSelector...), args...),
+	}, nil)
+	if err != nil {
+		return false, fmt.Errorf("calling isValidSignature: %w", err)
+	}
+
+	if len(result) < len(EIP1271MagicValue) {
+		return false, nil
+	}
+
+	return bytes.Equal(result[:len(EIP1271MagicValue)], EIP1271MagicValue), nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxBuilderFeeTenthsOfBps is the largest builder fee the exchange accepts (1%),
+// in tenths of a basis point
+const MaxBuilderFeeTenthsOfBps = 1000
+
+// PercentToTenthsOfBps converts an approveBuilderFee maxFeeRate such as "0.001%" to
+// the tenths of a basis point used by the order-level builder fee. The "%" suffix
+// is optional; the rate must be a whole number of tenths of a basis point between
+// 0 and MaxBuilderFeeTenthsOfBps
+func PercentToTenthsOfBps(pct string) (int, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(pct), "%")
+	if err := ValidateAmountString(trimmed); err != nil {
+		return 0, fmt.Errorf("fee rate %q: %w", pct, err)
+	}
+
+	d, err := decimal.NewFromString(trimmed)
+	if err != nil {
+		return 0, fmt.Errorf("parsing fee rate %q: %w", pct, err)
+	}
+
+	// 1% is 100 bps, i.e. 1000 tenths of a basis point
+	tenths := d.Shift(3)
+	if !tenths.IsInteger() {
+		return 0, fmt.Errorf("fee rate %q is not a whole number of tenths of a basis point", pct)
+	}
+	if tenths.GreaterThan(decimal.NewFromInt(MaxBuilderFeeTenthsOfBps)) {
+		return 0, fmt.Errorf("fee rate %q exceeds the maximum of %s", pct, TenthsOfBpsToPercent(MaxBuilderFeeTenthsOfBps))
+	}
+
+	return int(tenths.IntPart()), nil
+}
+
+// TenthsOfBpsToPercent formats a builder fee in tenths of a basis point as the
+// maxFeeRate string approveBuilderFee expects, e.g. 1 -> "0.001%"
+func TenthsOfBpsToPercent(tenths int) string {
+	return decimal.NewFromInt(int64(tenths)).Shift(-3).String() + "%"
+}
+
+// MaxBuilderFee returns the largest builder fee, in quote currency, that filling
+// every order of the batch could cost at feeTenthsBps: the summed notional of the
+// orders at their limit prices times the fee rate
+func MaxBuilderFee(orders []OrderWire, feeTenthsBps int) (float64, error) {
+	if feeTenthsBps < 0 || feeTenthsBps > MaxBuilderFeeTenthsOfBps {
+		return 0, fmt.Errorf("builder fee %d must be between 0 and %d tenths of a basis point", feeTenthsBps, MaxBuilderFeeTenthsOfBps)
+	}
+
+	total := decimal.Zero
+	for i, order := range orders {
+		px, err := decimal.NewFromString(order.Price)
+		if err != nil {
+			return 0, fmt.Errorf("order[%d] price %q: %w", i, order.Price, err)
+		}
+		sz, err := decimal.NewFromString(order.Size)
+		if err != nil {
+			return 0, fmt.Errorf("order[%d] size %q: %w", i, order.Size, err)
+		}
+		total = total.Add(px.Mul(sz))
+	}
+
+	// A tenth of a basis point is 1e-5 of the notional
+	fee := total.Mul(decimal.NewFromInt(int64(feeTenthsBps))).Shift(-5)
+	return fee.InexactFloat64(), nil
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// String summarizes the order type, e.g. "Gtc" or "sl trigger @ 2900 market"
+func (t OrderType) String() string {
+	switch {
+	case t.Limit != nil:
+		return string(t.Limit.TIF)
+	case t.Trigger != nil:
+		var b strings.Builder
+		b.Grow(32)
+		writeTrigger(&b, string(t.Trigger.TPSL), strconv.FormatFloat(t.Trigger.TriggerPx, 'f', -1, 64), t.Trigger.IsMarket)
+		return b.String()
+	default:
+		return "invalid"
+	}
+}
+
+// String summarizes the order, e.g. "BUY 0.5 ETH @ 3000 Gtc reduceOnly=false cloid=0x.."
+func (o OrderRequest) String() string {
+	var b strings.Builder
+	b.Grow(64)
+
+	writeSide(&b, o.IsBuy)
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(o.Size, 'f', -1, 64))
+	b.WriteByte(' ')
+	b.WriteString(o.Coin)
+	b.WriteString(" @ ")
+	b.WriteString(strconv.FormatFloat(o.LimitPrice, 'f', -1, 64))
+	b.WriteByte(' ')
+	b.WriteString(o.OrderType.String())
+	b.WriteString(" reduceOnly=")
+	b.WriteString(strconv.FormatBool(o.ReduceOnly))
+	if o.Cloid != nil {
+		b.WriteString(" cloid=")
+		b.WriteString(string(*o.Cloid))
+	}
+
+	return b.String()
+}
+
+// String summarizes the wire order, e.g. "BUY 0.5 asset=1 @ 3000 Gtc reduceOnly=false"
+func (w OrderWire) String() string {
+	var b strings.Builder
+	b.Grow(64)
+
+	writeSide(&b, w.IsBuy)
+	b.WriteByte(' ')
+	b.WriteString(w.Size)
+	b.WriteString(" asset=")
+	b.WriteString(strconv.Itoa(w.Asset))
+	b.WriteString(" @ ")
+	b.WriteString(w.Price)
+	b.WriteByte(' ')
+	switch {
+	case w.Type.Limit != nil:
+		b.WriteString(string(w.Type.Limit.TIF))
+	case w.Type.Trigger != nil:
+		writeTrigger(&b, string(w.Type.Trigger.TPSL), w.Type.Trigger.TriggerPx, w.Type.Trigger.IsMarket)
+	default:
+		b.WriteString("invalid")
+	}
+	b.WriteString(" reduceOnly=")
+	b.WriteString(strconv.FormatBool(w.ReduceOnly))
+	if w.Cloid != nil {
+		b.WriteString(" cloid=")
+		b.WriteString(*w.Cloid)
+	}
+
+	return b.String()
+}
+
+func writeSide(b *strings.Builder, isBuy bool) {
+	if isBuy {
+		b.WriteString("BUY")
+	} else {
+		b.WriteString("SELL")
+	}
+}
+
+func writeTrigger(b *strings.Builder, tpsl string, triggerPx string, isMarket bool) {
+	b.WriteString(tpsl)
+	b.WriteString(" trigger @ ")
+	b.WriteString(triggerPx)
+	if isMarket {
+		b.WriteString(" market")
+	} else {
+		b.WriteString(" limit")
+	}
+}
@@ -0,0 +1,531 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Mids maps each coin to its current mid price
+type Mids map[string]float64
+
+// MidPrice returns the mid price of coin, e.g. as the refPrice of CreateMarketOrder
+func (m Mids) MidPrice(coin string) (float64, bool) {
+	mid, ok := m[coin]
+	return mid, ok
+}
+
+// ParseAllMids decodes an allMids response, {"coin": "price", ...}
+func ParseAllMids(data []byte) (Mids, error) {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding mids: %w", err)
+	}
+
+	mids := make(Mids, len(raw))
+	for coin, px := range raw {
+		mid, err := SafeFloat64(px)
+		if err != nil {
+			return nil, fmt.Errorf("mid of %s: %w", coin, err)
+		}
+		mids[coin] = mid
+	}
+
+	return mids, nil
+}
+
+// OpenOrder is an order resting on the book, as returned by openOrders and
+// frontendOpenOrders. OrigSz, ReduceOnly and OrderType are only reported by
+// frontendOpenOrders
+type OpenOrder struct {
+	Coin       string
+	IsBuy      bool
+	LimitPx    float64
+	Sz         float64
+	OID        int64
+	Timestamp  int64
+	Cloid      *Cloid
+	OrigSz     float64
+	ReduceOnly bool
+	OrderType  string
+}
+
+type rawOpenOrder struct {
+	Coin       string `json:"coin"`
+	Side       string `json:"side"`
+	LimitPx    string `json:"limitPx"`
+	Sz         string `json:"sz"`
+	OID        int64  `json:"oid"`
+	Timestamp  int64  `json:"timestamp"`
+	Cloid      *Cloid `json:"cloid"`
+	OrigSz     string `json:"origSz"`
+	ReduceOnly bool   `json:"reduceOnly"`
+	OrderType  string `json:"orderType"`
+}
+
+// ParseOpenOrders decodes an openOrders or frontendOpenOrders response
+func ParseOpenOrders(data []byte) ([]OpenOrder, error) {
+	var raw []rawOpenOrder
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding open orders: %w", err)
+	}
+
+	orders := make([]OpenOrder, len(raw))
+	for i, r := range raw {
+		order, err := r.decode()
+		if err != nil {
+			return nil, err
+		}
+		orders[i] = order
+	}
+
+	return orders, nil
+}
+
+func (r rawOpenOrder) decode() (OpenOrder, error) {
+	isBuy, err := parseSide(r.Side)
+	if err != nil {
+		return OpenOrder{}, fmt.Errorf("open order %d: %w", r.OID, err)
+	}
+	limitPx, err := SafeFloat64(r.LimitPx)
+	if err != nil {
+		return OpenOrder{}, fmt.Errorf("open order %d limitPx: %w", r.OID, err)
+	}
+	sz, err := SafeFloat64(r.Sz)
+	if err != nil {
+		return OpenOrder{}, fmt.Errorf("open order %d sz: %w", r.OID, err)
+	}
+	origSz, err := parseOptionalFloat(r.OrigSz)
+	if err != nil {
+		return OpenOrder{}, fmt.Errorf("open order %d origSz: %w", r.OID, err)
+	}
+
+	return OpenOrder{
+		Coin:       r.Coin,
+		IsBuy:      isBuy,
+		LimitPx:    limitPx,
+		Sz:         sz,
+		OID:        r.OID,
+		Timestamp:  r.Timestamp,
+		Cloid:      r.Cloid,
+		OrigSz:     origSz,
+		ReduceOnly: r.ReduceOnly,
+		OrderType:  r.OrderType,
+	}, nil
+}
+
+// OrderUpdate is a status change of one of the user's orders, as streamed by the
+// orderUpdates subscription. Status is e.g. "open", "filled" or "canceled"
+type OrderUpdate struct {
+	Order           OpenOrder
+	Status          string
+	StatusTimestamp int64
+}
+
+// ParseOrderUpdates decodes the data of an orderUpdates subscription message
+func ParseOrderUpdates(data []byte) ([]OrderUpdate, error) {
+	var raw []struct {
+		Order           rawOpenOrder `json:"order"`
+		Status          string       `json:"status"`
+		StatusTimestamp int64        `json:"statusTimestamp"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding order updates: %w", err)
+	}
+
+	updates := make([]OrderUpdate, len(raw))
+	for i, r := range raw {
+		order, err := r.Order.decode()
+		if err != nil {
+			return nil, err
+		}
+		updates[i] = OrderUpdate{Order: order, Status: r.Status, StatusTimestamp: r.StatusTimestamp}
+	}
+
+	return updates, nil
+}
+
+// Trade is a public trade, as streamed by the trades subscription. Users holds the
+// buyer and seller addresses
+type Trade struct {
+	Coin  string
+	IsBuy bool
+	Px    float64
+	Sz    float64
+	Time  int64
+	Hash  string
+	TID   int64
+	Users [2]string
+}
+
+// ParseTrades decodes the data of a trades subscription message
+func ParseTrades(data []byte) ([]Trade, error) {
+	var raw []struct {
+		Coin  string    `json:"coin"`
+		Side  string    `json:"side"`
+		Px    string    `json:"px"`
+		Sz    string    `json:"sz"`
+		Time  int64     `json:"time"`
+		Hash  string    `json:"hash"`
+		TID   int64     `json:"tid"`
+		Users [2]string `json:"users"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding trades: %w", err)
+	}
+
+	trades := make([]Trade, len(raw))
+	for i, r := range raw {
+		isBuy, err := parseSide(r.Side)
+		if err != nil {
+			return nil, fmt.Errorf("trade %d: %w", r.TID, err)
+		}
+		px, err := SafeFloat64(r.Px)
+		if err != nil {
+			return nil, fmt.Errorf("trade %d px: %w", r.TID, err)
+		}
+		sz, err := SafeFloat64(r.Sz)
+		if err != nil {
+			return nil, fmt.Errorf("trade %d sz: %w", r.TID, err)
+		}
+
+		trades[i] = Trade{
+			Coin:  r.Coin,
+			IsBuy: isBuy,
+			Px:    px,
+			Sz:    sz,
+			Time:  r.Time,
+			Hash:  r.Hash,
+			TID:   r.TID,
+			Users: r.Users,
+		}
+	}
+
+	return trades, nil
+}
+
+// parseSide maps the "B" (bid) and "A" (ask) sides of a response to isBuy
+func parseSide(side string) (bool, error) {
+	switch Side(side) {
+	case SideBuy:
+		return true, nil
+	case SideSell:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown side: %q", side)
+	}
+}
+
+// parseOptionalFloat is SafeFloat64 for fields that may be absent, which parse as 0
+func parseOptionalFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return SafeFloat64(s)
+}
+
+// MarginSummary is an account's value and margin usage
+type MarginSummary struct {
+	AccountValue    float64
+	TotalNtlPos     float64
+	TotalRawUSD     float64
+	TotalMarginUsed float64
+}
+
+// Position is an open perp position. Szi is signed, negative for shorts. EntryPx
+// and LiquidationPx are 0 when the exchange reports none
+type Position struct {
+	Coin          string
+	Szi           float64
+	EntryPx       float64
+	Leverage      int
+	IsCross       bool
+	UnrealizedPnl float64
+	MarginUsed    float64
+	LiquidationPx float64
+}
+
+// UserState is the clearinghouse state of an account
+type UserState struct {
+	MarginSummary      MarginSummary
+	CrossMarginSummary MarginSummary
+	Withdrawable       float64
+	Positions          []Position
+}
+
+// Position returns the position in coin, if any
+func (s UserState) Position(coin string) (Position, bool) {
+	for _, p := range s.Positions {
+		if p.Coin == coin {
+			return p, true
+		}
+	}
+	return Position{}, false
+}
+
+type rawMarginSummary struct {
+	AccountValue    string `json:"accountValue"`
+	TotalNtlPos     string `json:"totalNtlPos"`
+	TotalRawUSD     string `json:"totalRawUsd"`
+	TotalMarginUsed string `json:"totalMarginUsed"`
+}
+
+type rawPosition struct {
+	Coin     string  `json:"coin"`
+	Szi      string  `json:"szi"`
+	EntryPx  *string `json:"entryPx"`
+	Leverage struct {
+		Type  string `json:"type"`
+		Value int    `json:"value"`
+	} `json:"leverage"`
+	UnrealizedPnl string  `json:"unrealizedPnl"`
+	MarginUsed    string  `json:"marginUsed"`
+	LiquidationPx *string `json:"liquidationPx"`
+}
+
+// ParseUserState decodes a clearinghouseState response
+func ParseUserState(data []byte) (UserState, error) {
+	var raw struct {
+		MarginSummary      rawMarginSummary `json:"marginSummary"`
+		CrossMarginSummary rawMarginSummary `json:"crossMarginSummary"`
+		Withdrawable       string           `json:"withdrawable"`
+		AssetPositions     []struct {
+			Position rawPosition `json:"position"`
+		} `json:"assetPositions"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return UserState{}, fmt.Errorf("decoding user state: %w", err)
+	}
+
+	marginSummary, err := raw.MarginSummary.parse()
+	if err != nil {
+		return UserState{}, fmt.Errorf("marginSummary: %w", err)
+	}
+	crossMarginSummary, err := raw.CrossMarginSummary.parse()
+	if err != nil {
+		return UserState{}, fmt.Errorf("crossMarginSummary: %w", err)
+	}
+	withdrawable, err := parseOptionalFloat(raw.Withdrawable)
+	if err != nil {
+		return UserState{}, fmt.Errorf("withdrawable: %w", err)
+	}
+
+	state := UserState{
+		MarginSummary:      marginSummary,
+		CrossMarginSummary: crossMarginSummary,
+		Withdrawable:       withdrawable,
+		Positions:          make([]Position, 0, len(raw.AssetPositions)),
+	}
+	for _, ap := range raw.AssetPositions {
+		position, err := ap.Position.parse()
+		if err != nil {
+			return UserState{}, fmt.Errorf("position in %s: %w", ap.Position.Coin, err)
+		}
+		state.Positions = append(state.Positions, position)
+	}
+
+	return state, nil
+}
+
+func (r rawMarginSummary) parse() (MarginSummary, error) {
+	var summary MarginSummary
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   *float64
+	}{
+		{"accountValue", r.AccountValue, &summary.AccountValue},
+		{"totalNtlPos", r.TotalNtlPos, &summary.TotalNtlPos},
+		{"totalRawUsd", r.TotalRawUSD, &summary.TotalRawUSD},
+		{"totalMarginUsed", r.TotalMarginUsed, &summary.TotalMarginUsed},
+	} {
+		f, err := parseOptionalFloat(field.value)
+		if err != nil {
+			return MarginSummary{}, fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.dst = f
+	}
+	return summary, nil
+}
+
+func (r rawPosition) parse() (Position, error) {
+	position := Position{
+		Coin:     r.Coin,
+		Leverage: r.Leverage.Value,
+		IsCross:  r.Leverage.Type == "cross",
+	}
+
+	var entryPx, liquidationPx string
+	if r.EntryPx != nil {
+		entryPx = *r.EntryPx
+	}
+	if r.LiquidationPx != nil {
+		liquidationPx = *r.LiquidationPx
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   *float64
+	}{
+		{"szi", r.Szi, &position.Szi},
+		{"entryPx", entryPx, &position.EntryPx},
+		{"unrealizedPnl", r.UnrealizedPnl, &position.UnrealizedPnl},
+		{"marginUsed", r.MarginUsed, &position.MarginUsed},
+		{"liquidationPx", liquidationPx, &position.LiquidationPx},
+	} {
+		f, err := parseOptionalFloat(field.value)
+		if err != nil {
+			return Position{}, fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.dst = f
+	}
+	return position, nil
+}
+
+// Fill is an executed trade of the user, as returned by userFills and
+// userFillsByTime. Sz is unsigned; StartPosition is the signed position before it
+type Fill struct {
+	Coin          string
+	Px            float64
+	Sz            float64
+	IsBuy         bool
+	Time          int64
+	StartPosition float64
+	Dir           string
+	ClosedPnl     float64
+	Hash          string
+	OID           int64
+	Crossed       bool
+	Fee           float64
+	BuilderFee    float64 // fee paid to the order's builder, if any
+	Cloid         *Cloid
+}
+
+// SignedSz returns the fill size signed by side, positive for buys
+func (f Fill) SignedSz() float64 {
+	if f.IsBuy {
+		return f.Sz
+	}
+	return -f.Sz
+}
+
+type rawFill struct {
+	Coin          string `json:"coin"`
+	Px            string `json:"px"`
+	Sz            string `json:"sz"`
+	Side          string `json:"side"`
+	Time          int64  `json:"time"`
+	StartPosition string `json:"startPosition"`
+	Dir           string `json:"dir"`
+	ClosedPnl     string `json:"closedPnl"`
+	Hash          string `json:"hash"`
+	OID           int64  `json:"oid"`
+	Crossed       bool   `json:"crossed"`
+	Fee           string `json:"fee"`
+	BuilderFee    string `json:"builderFee"`
+	Cloid         *Cloid `json:"cloid"`
+}
+
+// ParseFills decodes a userFills or userFillsByTime response
+func ParseFills(data []byte) ([]Fill, error) {
+	var raw []rawFill
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding fills: %w", err)
+	}
+
+	fills := make([]Fill, len(raw))
+	for i, r := range raw {
+		isBuy, err := parseSide(r.Side)
+		if err != nil {
+			return nil, fmt.Errorf("fill %d: %w", i, err)
+		}
+
+		fill := Fill{
+			Coin:    r.Coin,
+			IsBuy:   isBuy,
+			Time:    r.Time,
+			Dir:     r.Dir,
+			Hash:    r.Hash,
+			OID:     r.OID,
+			Crossed: r.Crossed,
+			Cloid:   r.Cloid,
+		}
+		for _, field := range []struct {
+			name  string
+			value string
+			dst   *float64
+		}{
+			{"px", r.Px, &fill.Px},
+			{"sz", r.Sz, &fill.Sz},
+			{"startPosition", r.StartPosition, &fill.StartPosition},
+			{"closedPnl", r.ClosedPnl, &fill.ClosedPnl},
+			{"fee", r.Fee, &fill.Fee},
+			{"builderFee", r.BuilderFee, &fill.BuilderFee},
+		} {
+			f, err := parseOptionalFloat(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("fill %d %s: %w", i, field.name, err)
+			}
+			*field.dst = f
+		}
+		fills[i] = fill
+	}
+
+	return fills, nil
+}
+
+// LatestFillTime returns the latest fill time in fills, or 0 when empty. Passing
+// it plus one as the startTime of the next userFillsByTime request pages forward
+func LatestFillTime(fills []Fill) int64 {
+	var latest int64
+	for _, f := range fills {
+		if f.Time > latest {
+			latest = f.Time
+		}
+	}
+	return latest
+}
+
+// CoinPnL is the realized PnL, fees and traded notional of one coin
+type CoinPnL struct {
+	RealizedPnL float64
+	Fees        float64
+	BuilderFees float64
+	Volume      float64
+}
+
+// PnLSummary aggregates fills: RealizedPnL sums their closedPnl, Fees and
+// BuilderFees their fee and builderFee, and Volume their notional
+type PnLSummary struct {
+	RealizedPnL float64
+	Fees        float64
+	BuilderFees float64
+	Volume      float64
+	ByCoin      map[string]CoinPnL
+}
+
+// NetPnL returns the realized PnL after exchange fees
+func (s PnLSummary) NetPnL() float64 {
+	return s.RealizedPnL - s.Fees
+}
+
+// SummarizePnL aggregates fills into a realized PnL summary
+func SummarizePnL(fills []Fill) PnLSummary {
+	summary := PnLSummary{ByCoin: make(map[string]CoinPnL)}
+	for _, f := range fills {
+		volume := f.Px * f.Sz
+
+		summary.RealizedPnL += f.ClosedPnl
+		summary.Fees += f.Fee
+		summary.BuilderFees += f.BuilderFee
+		summary.Volume += volume
+
+		coin := summary.ByCoin[f.Coin]
+		coin.RealizedPnL += f.ClosedPnl
+		coin.Fees += f.Fee
+		coin.BuilderFees += f.BuilderFee
+		coin.Volume += volume
+		summary.ByCoin[f.Coin] = coin
+	}
+	return summary
+}
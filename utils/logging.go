@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Logger receives debug output from the signing pipeline; keyvals alternate
+// between string keys and their values
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+
+type loggerHolder struct {
+	logger  Logger
+	enabled bool
+}
+
+var signingLogger atomic.Pointer[loggerHolder]
+
+func init() {
+	signingLogger.Store(&loggerHolder{logger: nopLogger{}})
+}
+
+// SetSigningLogger sets the logger SignL1Action and SignUserSignedAction report to;
+// a nil logger restores the default no-op logger
+func SetSigningLogger(logger Logger) {
+	if logger == nil {
+		signingLogger.Store(&loggerHolder{logger: nopLogger{}})
+		return
+	}
+	signingLogger.Store(&loggerHolder{logger: logger, enabled: true})
+}
+
+// logSignature logs the primary type, connection ID (L1 actions only) and the signer
+// recovered from sig over encodedData. It does nothing unless a logger is set
+func logSignature(primaryType string, connectionID []byte, encodedData []byte, sig Signature) {
+	holder := signingLogger.Load()
+	if !holder.enabled {
+		return
+	}
+
+	keyvals := []interface{}{"primaryType", primaryType}
+	if connectionID != nil {
+		keyvals = append(keyvals, "connectionId", hexutil.Encode(connectionID))
+	}
+
+	signer, err := recoverSigner(encodedData, sig)
+	if err != nil {
+		keyvals = append(keyvals, "recoverError", err)
+	} else {
+		keyvals = append(keyvals, "signer", signer)
+	}
+
+	holder.logger.Debug("signed action", keyvals...)
+}
+
+// recoverSigner returns the address that produced sig over message
+func recoverSigner(message []byte, sig Signature) (string, error) {
+	r, err := hexutil.Decode(sig.R)
+	if err != nil {
+		return "", fmt.Errorf("invalid R value: %w", err)
+	}
+
+	s, err := hexutil.Decode(sig.S)
+	if err != nil {
+		return "", fmt.Errorf("invalid S value: %w", err)
+	}
+
+	v := sig.V
+	if v >= 27 {
+		v -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(HashMessage(message), append(append(r, s...), v))
+	if err != nil {
+		return "", fmt.Errorf("recovering public key: %w", err)
+	}
+
+	return crypto.PubkeyToAddress(*pubKey).Hex(), nil
+}
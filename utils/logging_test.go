@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+)
+
+type recordingLogger struct {
+	keyvals [][]interface{}
+}
+
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) {
+	l.keyvals = append(l.keyvals, keyvals)
+}
+
+func TestSigningLoggerUserSignedAction(t *testing.T) {
+	logger := &recordingLogger{}
+	SetSigningLogger(logger)
+	defer SetSigningLogger(nil)
+
+	wallet := testWallet(t)
+	action := CreateUSDTransferAction("0x1719884eb866cb12b2287399b15f7db5e7d775ea", "1", fixtureNonce)
+	if _, err := SignUSDTransferAction(wallet, action, true); err != nil {
+		t.Fatalf("SignUSDTransferAction: %v", err)
+	}
+
+	if len(logger.keyvals) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(logger.keyvals))
+	}
+	want := []interface{}{"primaryType", "HyperliquidTransaction:UsdSend", "signer", wallet.Address().Hex()}
+	got := logger.keyvals[0]
+	if len(got) != len(want) {
+		t.Fatalf("keyvals = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("keyvals = %v, want %v", got, want)
+		}
+	}
+
+	SetSigningLogger(nil)
+	if _, err := SignUSDTransferAction(wallet, action, true); err != nil {
+		t.Fatalf("SignUSDTransferAction: %v", err)
+	}
+	if len(logger.keyvals) != 1 {
+		t.Errorf("disabled logger still received %d entries", len(logger.keyvals)-1)
+	}
+}
@@ -0,0 +1,334 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// SpotAssetOffset is added to a spot pair index to obtain its asset ID
+	SpotAssetOffset = 10000
+
+	PerpMaxPriceDecimals = 6
+	SpotMaxPriceDecimals = 8
+	MaxPriceSigFigs      = 5
+)
+
+// AssetInfo holds the per-asset metadata needed to build valid orders
+type AssetInfo struct {
+	Index      int
+	SzDecimals int
+	IsSpot     bool
+}
+
+// AssetRegistry indexes AssetInfo by coin name
+type AssetRegistry struct {
+	assets map[string]AssetInfo
+}
+
+type rawPerpMeta struct {
+	Universe []struct {
+		Name       string `json:"name"`
+		SzDecimals int    `json:"szDecimals"`
+	} `json:"universe"`
+}
+
+type rawSpotMeta struct {
+	Universe []struct {
+		Name   string `json:"name"`
+		Tokens []int  `json:"tokens"`
+		Index  int    `json:"index"`
+	} `json:"universe"`
+	Tokens []struct {
+		Name       string `json:"name"`
+		SzDecimals int    `json:"szDecimals"`
+		Index      int    `json:"index"`
+	} `json:"tokens"`
+}
+
+// BuildAssetMap builds the coin to asset index map from a perp "meta" response
+func BuildAssetMap(metaJSON []byte) (map[string]int, error) {
+	var meta rawPerpMeta
+	if err := json.Unmarshal(metaJSON, &meta); err != nil {
+		return nil, fmt.Errorf("decoding meta: %w", err)
+	}
+
+	assetMap := make(map[string]int, len(meta.Universe))
+	for i, asset := range meta.Universe {
+		assetMap[asset.Name] = i
+	}
+
+	return assetMap, nil
+}
+
+// BuildSpotAssetMap builds the coin to asset index and coin to szDecimals maps from
+// a "spotMeta" response; see SpotMeta.AssetMaps
+func BuildSpotAssetMap(spotMetaJSON []byte) (map[string]int, map[string]int, error) {
+	meta, err := ParseSpotMeta(spotMetaJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta.AssetMaps()
+}
+
+// SpotToken is a token listed in a "spotMeta" response
+type SpotToken struct {
+	Name       string
+	SzDecimals int
+	Index      int
+}
+
+// SpotPair is a spot trading pair; Tokens holds the base and quote token indices
+type SpotPair struct {
+	Name   string
+	Tokens []int
+	Index  int
+}
+
+// SpotMeta is a decoded "spotMeta" response
+type SpotMeta struct {
+	Universe []SpotPair
+	Tokens   []SpotToken
+}
+
+// ParseSpotMeta decodes a "spotMeta" response
+func ParseSpotMeta(data []byte) (SpotMeta, error) {
+	var raw rawSpotMeta
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return SpotMeta{}, fmt.Errorf("decoding spot meta: %w", err)
+	}
+
+	meta := SpotMeta{
+		Universe: make([]SpotPair, len(raw.Universe)),
+		Tokens:   make([]SpotToken, len(raw.Tokens)),
+	}
+	for i, pair := range raw.Universe {
+		meta.Universe[i] = SpotPair(pair)
+	}
+	for i, token := range raw.Tokens {
+		meta.Tokens[i] = SpotToken(token)
+	}
+
+	return meta, nil
+}
+
+// AssetMaps returns the coin to asset index and coin to szDecimals maps. Asset
+// indices are offset by SpotAssetOffset and szDecimals are taken from the pair's
+// base token
+func (m SpotMeta) AssetMaps() (map[string]int, map[string]int, error) {
+	tokenDecimals := make(map[int]int, len(m.Tokens))
+	for _, token := range m.Tokens {
+		tokenDecimals[token.Index] = token.SzDecimals
+	}
+
+	assetMap := make(map[string]int, len(m.Universe))
+	szDecimals := make(map[string]int, len(m.Universe))
+	for _, pair := range m.Universe {
+		if len(pair.Tokens) != 2 {
+			return nil, nil, fmt.Errorf("spot pair %s: expected 2 tokens, got %d", pair.Name, len(pair.Tokens))
+		}
+		decimals, ok := tokenDecimals[pair.Tokens[0]]
+		if !ok {
+			return nil, nil, fmt.Errorf("spot pair %s: unknown base token %d", pair.Name, pair.Tokens[0])
+		}
+
+		assetMap[pair.Name] = SpotAssetOffset + pair.Index
+		szDecimals[pair.Name] = decimals
+	}
+
+	return assetMap, szDecimals, nil
+}
+
+// AssetMeta is the metadata of one perp asset from a "meta" response
+type AssetMeta struct {
+	Name          string
+	SzDecimals    int
+	MaxLeverage   int
+	OnlyIsolated  bool
+	IsDelisted    bool
+	MarginTableID int
+}
+
+// MarginTier is a notional bracket of a margin table: positions whose notional is
+// at least LowerBound may use at most MaxLeverage
+type MarginTier struct {
+	LowerBound  float64
+	MaxLeverage int
+}
+
+// MarginTable is a set of margin tiers, ordered by increasing LowerBound
+type MarginTable struct {
+	Description string
+	Tiers       []MarginTier
+}
+
+// Meta is a decoded perp "meta" response. Universe is ordered by asset index
+type Meta struct {
+	Universe     []AssetMeta
+	MarginTables map[int]MarginTable
+}
+
+// Asset returns the metadata and asset index of coin
+func (m Meta) Asset(coin string) (AssetMeta, int, bool) {
+	for i, asset := range m.Universe {
+		if asset.Name == coin {
+			return asset, i, true
+		}
+	}
+	return AssetMeta{}, 0, false
+}
+
+// AssetMap returns the coin to asset index map expected by BatchOrdersToWire
+func (m Meta) AssetMap() map[string]int {
+	assetMap := make(map[string]int, len(m.Universe))
+	for i, asset := range m.Universe {
+		assetMap[asset.Name] = i
+	}
+	return assetMap
+}
+
+// ParseMeta decodes a perp "meta" response, including its margin tables
+func ParseMeta(data []byte) (Meta, error) {
+	var raw struct {
+		Universe []struct {
+			Name          string `json:"name"`
+			SzDecimals    int    `json:"szDecimals"`
+			MaxLeverage   int    `json:"maxLeverage"`
+			OnlyIsolated  bool   `json:"onlyIsolated"`
+			IsDelisted    bool   `json:"isDelisted"`
+			MarginTableID int    `json:"marginTableId"`
+		} `json:"universe"`
+		MarginTables [][2]json.RawMessage `json:"marginTables"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Meta{}, fmt.Errorf("decoding meta: %w", err)
+	}
+
+	meta := Meta{
+		Universe:     make([]AssetMeta, len(raw.Universe)),
+		MarginTables: make(map[int]MarginTable, len(raw.MarginTables)),
+	}
+	for i, asset := range raw.Universe {
+		meta.Universe[i] = AssetMeta(asset)
+	}
+
+	// Margin tables are encoded as [id, {"description": .., "marginTiers": [..]}] pairs
+	for _, entry := range raw.MarginTables {
+		var id int
+		if err := json.Unmarshal(entry[0], &id); err != nil {
+			return Meta{}, fmt.Errorf("decoding margin table id: %w", err)
+		}
+
+		var table struct {
+			Description string `json:"description"`
+			MarginTiers []struct {
+				LowerBound  string `json:"lowerBound"`
+				MaxLeverage int    `json:"maxLeverage"`
+			} `json:"marginTiers"`
+		}
+		if err := json.Unmarshal(entry[1], &table); err != nil {
+			return Meta{}, fmt.Errorf("decoding margin table %d: %w", id, err)
+		}
+
+		tiers := make([]MarginTier, len(table.MarginTiers))
+		for i, tier := range table.MarginTiers {
+			lowerBound, err := SafeFloat64(tier.LowerBound)
+			if err != nil {
+				return Meta{}, fmt.Errorf("margin table %d tier %d lowerBound: %w", id, i, err)
+			}
+			tiers[i] = MarginTier{LowerBound: lowerBound, MaxLeverage: tier.MaxLeverage}
+		}
+		meta.MarginTables[id] = MarginTable{Description: table.Description, Tiers: tiers}
+	}
+
+	return meta, nil
+}
+
+// NewAssetRegistry builds a registry from a perp "meta" response and an optional
+// "spotMeta" response (pass nil to skip spot assets)
+func NewAssetRegistry(metaJSON []byte, spotMetaJSON []byte) (*AssetRegistry, error) {
+	meta, err := ParseMeta(metaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if spotMetaJSON == nil {
+		return NewAssetRegistryFromMeta(meta, nil)
+	}
+
+	spotMeta, err := ParseSpotMeta(spotMetaJSON)
+	if err != nil {
+		return nil, err
+	}
+	return NewAssetRegistryFromMeta(meta, &spotMeta)
+}
+
+// NewAssetRegistryFromMeta builds a registry from decoded perp and optional spot
+// metadata (pass nil to skip spot assets)
+func NewAssetRegistryFromMeta(meta Meta, spotMeta *SpotMeta) (*AssetRegistry, error) {
+	reg := &AssetRegistry{assets: make(map[string]AssetInfo, len(meta.Universe))}
+	for i, asset := range meta.Universe {
+		reg.assets[asset.Name] = AssetInfo{Index: i, SzDecimals: asset.SzDecimals}
+	}
+
+	if spotMeta != nil {
+		assetMap, szDecimals, err := spotMeta.AssetMaps()
+		if err != nil {
+			return nil, err
+		}
+		for coin, index := range assetMap {
+			reg.assets[coin] = AssetInfo{Index: index, SzDecimals: szDecimals[coin], IsSpot: true}
+		}
+	}
+
+	return reg, nil
+}
+
+// Lookup returns the metadata registered for coin
+func (r *AssetRegistry) Lookup(coin string) (AssetInfo, bool) {
+	info, ok := r.assets[coin]
+	return info, ok
+}
+
+// AssetMap returns the coin to asset index map expected by BatchOrdersToWire
+func (r *AssetRegistry) AssetMap() map[string]int {
+	assetMap := make(map[string]int, len(r.assets))
+	for coin, info := range r.assets {
+		assetMap[coin] = info.Index
+	}
+	return assetMap
+}
+
+// PriceDecimals returns the maximum number of decimals allowed for a price
+func (a AssetInfo) PriceDecimals() int {
+	maxDecimals := PerpMaxPriceDecimals
+	if a.IsSpot {
+		maxDecimals = SpotMaxPriceDecimals
+	}
+	if decimals := maxDecimals - a.SzDecimals; decimals > 0 {
+		return decimals
+	}
+	return 0
+}
+
+// RoundPrice rounds px to MaxPriceSigFigs significant figures and to the asset's
+// price decimals. Integer prices are always valid and are returned unchanged
+func (a AssetInfo) RoundPrice(px float64) float64 {
+	if px == math.Trunc(px) {
+		return px
+	}
+
+	sigFigs, err := strconv.ParseFloat(strconv.FormatFloat(px, 'g', MaxPriceSigFigs, 64), 64)
+	if err != nil {
+		return RoundFloat64(px, a.PriceDecimals())
+	}
+
+	return RoundFloat64(sigFigs, a.PriceDecimals())
+}
+
+// RoundSize rounds sz to the asset's szDecimals
+func (a AssetInfo) RoundSize(sz float64) float64 {
+	return RoundFloat64(sz, a.SzDecimals)
+}
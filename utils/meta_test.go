@@ -0,0 +1,54 @@
+package utils
+
+import "testing"
+
+const (
+	fixtureMetaJSON     = `{"universe":[{"name":"BTC","szDecimals":5,"maxLeverage":40},{"name":"ETH","szDecimals":4,"maxLeverage":25}],"marginTables":[]}`
+	fixtureSpotMetaJSON = `{"universe":[{"name":"PURR/USDC","tokens":[1,0],"index":0},{"name":"@107","tokens":[150,0],"index":107}],"tokens":[{"name":"USDC","szDecimals":8,"index":0},{"name":"PURR","szDecimals":0,"index":1},{"name":"HYPE","szDecimals":2,"index":150}]}`
+)
+
+func TestNewAssetRegistryFromMeta(t *testing.T) {
+	meta, err := ParseMeta([]byte(fixtureMetaJSON))
+	if err != nil {
+		t.Fatalf("ParseMeta: %v", err)
+	}
+	spotMeta, err := ParseSpotMeta([]byte(fixtureSpotMetaJSON))
+	if err != nil {
+		t.Fatalf("ParseSpotMeta: %v", err)
+	}
+
+	reg, err := NewAssetRegistryFromMeta(meta, &spotMeta)
+	if err != nil {
+		t.Fatalf("NewAssetRegistryFromMeta: %v", err)
+	}
+
+	tests := []struct {
+		coin string
+		want AssetInfo
+	}{
+		{"BTC", AssetInfo{Index: 0, SzDecimals: 5}},
+		{"ETH", AssetInfo{Index: 1, SzDecimals: 4}},
+		{"PURR/USDC", AssetInfo{Index: 10000, SzDecimals: 0, IsSpot: true}},
+		{"@107", AssetInfo{Index: 10107, SzDecimals: 2, IsSpot: true}},
+	}
+	for _, tt := range tests {
+		got, ok := reg.Lookup(tt.coin)
+		if !ok {
+			t.Errorf("Lookup(%q) missing", tt.coin)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, want %+v", tt.coin, got, tt.want)
+		}
+	}
+
+	fromJSON, err := NewAssetRegistry([]byte(fixtureMetaJSON), []byte(fixtureSpotMetaJSON))
+	if err != nil {
+		t.Fatalf("NewAssetRegistry: %v", err)
+	}
+	for coin, info := range reg.assets {
+		if got, _ := fromJSON.Lookup(coin); got != info {
+			t.Errorf("NewAssetRegistry Lookup(%q) = %+v, want %+v", coin, got, info)
+		}
+	}
+}
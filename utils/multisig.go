@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -8,6 +10,80 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
+var ErrUnknownMultiSigUser = errors.New("unknown multi-sig user")
+
+// MultiSigConfig describes a multi-sig account: the converted account's address and
+// the authorized signers and threshold set by convertToMultiSigUser
+type MultiSigConfig struct {
+	User            string
+	AuthorizedUsers []string
+	Threshold       int
+}
+
+// MultiSigConfigFromSigners builds the config of user from the JSON "signers" field
+// of its convertToMultiSigUser action, {"authorizedUsers":[...],"threshold":N}
+func MultiSigConfigFromSigners(user string, signers string) (MultiSigConfig, error) {
+	var raw struct {
+		AuthorizedUsers []string `json:"authorizedUsers"`
+		Threshold       int      `json:"threshold"`
+	}
+	if err := json.Unmarshal([]byte(signers), &raw); err != nil {
+		return MultiSigConfig{}, fmt.Errorf("decoding signers: %w", err)
+	}
+
+	config := MultiSigConfig{
+		User:            user,
+		AuthorizedUsers: raw.AuthorizedUsers,
+		Threshold:       raw.Threshold,
+	}
+	if err := config.Validate(); err != nil {
+		return MultiSigConfig{}, err
+	}
+
+	return config, nil
+}
+
+func (c MultiSigConfig) Validate() error {
+	if !common.IsHexAddress(c.User) {
+		return fmt.Errorf("%w: multi-sig user", ErrInvalidAddress)
+	}
+	for _, user := range c.AuthorizedUsers {
+		if !common.IsHexAddress(user) {
+			return fmt.Errorf("%w: authorized user %s", ErrInvalidAddress, user)
+		}
+	}
+	if c.Threshold <= 0 || c.Threshold > len(c.AuthorizedUsers) {
+		return fmt.Errorf("threshold %d must be between 1 and %d authorized users", c.Threshold, len(c.AuthorizedUsers))
+	}
+	return nil
+}
+
+// IsAuthorized reports whether addr is one of the authorized signers
+func (c MultiSigConfig) IsAuthorized(addr string) bool {
+	for _, user := range c.AuthorizedUsers {
+		if sameAddress(user, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePayloadMultiSigUser checks that payloadMultiSigUser is one of the known
+// multi-sig accounts and that outerSigner is authorized on it, returning its config
+func ValidatePayloadMultiSigUser(payloadMultiSigUser, outerSigner string, known []MultiSigConfig) (MultiSigConfig, error) {
+	for _, config := range known {
+		if !sameAddress(config.User, payloadMultiSigUser) {
+			continue
+		}
+		if !config.IsAuthorized(outerSigner) {
+			return MultiSigConfig{}, fmt.Errorf("outer signer %s is not authorized on multi-sig user %s", outerSigner, payloadMultiSigUser)
+		}
+		return config, nil
+	}
+
+	return MultiSigConfig{}, fmt.Errorf("%w: %s", ErrUnknownMultiSigUser, payloadMultiSigUser)
+}
+
 func AddMultiSigTypes(signTypes []SignatureType) ([]SignatureType, error) {
 	enrichedSignTypes := make([]SignatureType, 0, len(signTypes)+2)
 	enriched := false
@@ -96,9 +172,26 @@ func SignMultiSigL1ActionPayload(
 	return SignL1Action(wallet, envelope, vaultAddress, timestamp, isMainnet)
 }
 
+// MultiSigPayload is the payload of a multiSig action: Action signed by the
+// authorized users on behalf of MultiSigUser
+type MultiSigPayload struct {
+	MultiSigUser string      `json:"multiSigUser" msgpack:"multiSigUser"`
+	OuterSigner  string      `json:"outerSigner" msgpack:"outerSigner"`
+	Action       interface{} `json:"action" msgpack:"action"`
+}
+
+// MultiSigAction is the multiSig action. Its fields are declared in the order the
+// exchange hashes them; SignMultiSigAction hashes it without the type
+type MultiSigAction struct {
+	Type             string          `json:"type,omitempty" msgpack:"type,omitempty"`
+	SignatureChainID string          `json:"signatureChainId" msgpack:"signatureChainId"`
+	Signatures       []Signature     `json:"signatures" msgpack:"signatures"`
+	Payload          MultiSigPayload `json:"payload" msgpack:"payload"`
+}
+
 func SignMultiSigAction(
 	wallet Wallet,
-	action map[string]interface{},
+	action MultiSigAction,
 	isMainnet bool,
 	vaultAddress string,
 	nonce uint64,
@@ -107,12 +200,8 @@ func SignMultiSigAction(
 		return Signature{}, fmt.Errorf("%w: vaultAddress", ErrInvalidAddress)
 	}
 
-	actionWithoutTag := make(map[string]interface{}, len(action)-1)
-	for k, v := range action {
-		if k != "type" {
-			actionWithoutTag[k] = v
-		}
-	}
+	actionWithoutTag := action
+	actionWithoutTag.Type = ""
 
 	multiSigActionHash, err := ActionHash(actionWithoutTag, vaultAddress, nonce)
 	if err != nil {
@@ -133,30 +222,96 @@ func SignMultiSigAction(
 	)
 }
 
+// CreateMultiSigAction tags action as a multiSig action, has wallet sign it as the
+// outer signer with SignMultiSigAction and bundles the result for submission
 func CreateMultiSigAction(
-	innerAction map[string]interface{},
+	action MultiSigAction,
 	wallet Wallet,
 	isMainnet bool,
 	vaultAddress string,
 	nonce uint64,
-) (map[string]interface{}, Signature, error) {
-	sig, err := SignMultiSigAction(wallet, innerAction, isMainnet, vaultAddress, nonce)
+) (SignedAction, error) {
+	action.Type = "multiSig"
+
+	sig, err := SignMultiSigAction(wallet, action, isMainnet, vaultAddress, nonce)
 	if err != nil {
-		return nil, Signature{}, fmt.Errorf("signing multi-sig action: %w", err)
+		return SignedAction{}, fmt.Errorf("signing multi-sig action: %w", err)
 	}
 
-	envelope := map[string]interface{}{
-		"type":         "sendMultiSig",
-		"wallet":       strings.ToLower(wallet.Address().Hex()),
-		"vaultAddress": vaultAddress,
-		"nonce":        nonce,
-		"action":       innerAction,
-		"signature": map[string]interface{}{
-			"r": sig.R,
-			"s": sig.S,
-			"v": sig.V,
+	return SignedAction{
+		Action:       action,
+		Nonce:        nonce,
+		VaultAddress: vaultAddress,
+		Signature:    sig,
+	}, nil
+}
+
+// BuildMultiSigOrderEnvelope has every signer sign an order action on behalf of
+// config.User with SignMultiSigL1ActionPayload, wraps the action and signatures in
+// a multiSig action and has outerSigner sign it with CreateMultiSigAction. Signers
+// and the outer signer must be authorized on config, and there must be at least
+// config.Threshold distinct signers
+func BuildMultiSigOrderEnvelope(
+	orders []OrderWire,
+	grouping GroupingType,
+	signers []Wallet,
+	config MultiSigConfig,
+	outerSigner Wallet,
+	vaultAddress string,
+	nonce uint64,
+	isMainnet bool,
+) (SignedAction, error) {
+	if len(orders) == 0 {
+		return SignedAction{}, fmt.Errorf("no orders provided")
+	}
+	if err := config.Validate(); err != nil {
+		return SignedAction{}, fmt.Errorf("invalid multi-sig config: %w", err)
+	}
+
+	outer := outerSigner.Address().Hex()
+	if !config.IsAuthorized(outer) {
+		return SignedAction{}, fmt.Errorf("outer signer %s is not authorized on multi-sig user %s", outer, config.User)
+	}
+
+	seen := make(map[common.Address]bool, len(signers))
+	for _, signer := range signers {
+		addr := signer.Address()
+		if seen[addr] {
+			return SignedAction{}, fmt.Errorf("duplicate signer: %s", addr.Hex())
+		}
+		if !config.IsAuthorized(addr.Hex()) {
+			return SignedAction{}, fmt.Errorf("signer %s is not authorized on multi-sig user %s", addr.Hex(), config.User)
+		}
+		seen[addr] = true
+	}
+	if len(signers) < config.Threshold {
+		return SignedAction{}, fmt.Errorf("%d signers do not meet threshold %d", len(signers), config.Threshold)
+	}
+
+	action := OrderAction{
+		Type:     "order",
+		Orders:   orders,
+		Grouping: grouping,
+	}
+
+	signatures := make([]Signature, 0, len(signers))
+	for _, signer := range signers {
+		sig, err := SignMultiSigL1ActionPayload(signer, action, isMainnet, vaultAddress, nonce, config.User, outer)
+		if err != nil {
+			return SignedAction{}, fmt.Errorf("signing for %s: %w", signer.Address().Hex(), err)
+		}
+		signatures = append(signatures, sig)
+	}
+
+	multiSig := MultiSigAction{
+		SignatureChainID: UserSignedActionDomain(isMainnet).SignatureChainID(),
+		Signatures:       signatures,
+		Payload: MultiSigPayload{
+			MultiSigUser: strings.ToLower(config.User),
+			OuterSigner:  strings.ToLower(outer),
+			Action:       action,
 		},
 	}
 
-	return envelope, sig, nil
+	return CreateMultiSigAction(multiSig, outerSigner, isMainnet, vaultAddress, nonce)
 }
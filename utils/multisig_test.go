@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSignMultiSigActionIgnoresType(t *testing.T) {
+	wallet := testWallet(t)
+	action := MultiSigAction{
+		SignatureChainID: "0x66eee",
+		Payload: MultiSigPayload{
+			MultiSigUser: "0x1719884eb866cb12b2287399b15f7db5e7d775ea",
+			OuterSigner:  "0x14791697260e4c9a71f18484c9f997b308e59325",
+			Action:       fixtureCancelAction(),
+		},
+	}
+
+	untagged, err := SignMultiSigAction(wallet, action, false, "", fixtureNonce)
+	if err != nil {
+		t.Fatalf("SignMultiSigAction: %v", err)
+	}
+
+	action.Type = "multiSig"
+	tagged, err := SignMultiSigAction(wallet, action, false, "", fixtureNonce)
+	if err != nil {
+		t.Fatalf("SignMultiSigAction: %v", err)
+	}
+	if tagged != untagged {
+		t.Errorf("type changed the signature: %+v != %+v", tagged, untagged)
+	}
+}
+
+// keys returns the sorted keys of the JSON object m
+func keys(m map[string]interface{}) string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+func TestBuildMultiSigOrderEnvelope(t *testing.T) {
+	const multiSigUser = "0x1719884EB866cb12b2287399B15f7db5e7d775EA"
+	outer := testWallet(t)
+	cosigner, err := NewPrivateKeyWallet("0x0000000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("NewPrivateKeyWallet: %v", err)
+	}
+	stranger, err := NewPrivateKeyWallet("0x0000000000000000000000000000000000000000000000000000000000000002")
+	if err != nil {
+		t.Fatalf("NewPrivateKeyWallet: %v", err)
+	}
+	config := MultiSigConfig{
+		User:            multiSigUser,
+		AuthorizedUsers: []string{outer.Address().Hex(), cosigner.Address().Hex()},
+		Threshold:       2,
+	}
+	orders := fixtureOrderAction(t, nil).Orders
+
+	t.Run("exchange shape", func(t *testing.T) {
+		signed, err := BuildMultiSigOrderEnvelope(orders, GroupingNA, []Wallet{outer, cosigner}, config, outer, "", fixtureNonce, false)
+		if err != nil {
+			t.Fatalf("BuildMultiSigOrderEnvelope: %v", err)
+		}
+
+		body, err := json.Marshal(signed.ToPayload())
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+
+		if got := keys(payload); got != "action,nonce,signature,vaultAddress" {
+			t.Fatalf("payload keys = %s", got)
+		}
+		if v, ok := payload["vaultAddress"]; !ok || v != nil {
+			t.Errorf("vaultAddress = %v, want null", v)
+		}
+		action := payload["action"].(map[string]interface{})
+		if got := keys(action); got != "payload,signatureChainId,signatures,type" {
+			t.Fatalf("action keys = %s", got)
+		}
+		if action["type"] != "multiSig" || action["signatureChainId"] != "0x66eee" {
+			t.Errorf("action type = %v, signatureChainId = %v", action["type"], action["signatureChainId"])
+		}
+		if sigs := action["signatures"].([]interface{}); len(sigs) != 2 {
+			t.Errorf("%d signatures, want 2", len(sigs))
+		}
+		inner := action["payload"].(map[string]interface{})
+		if got := keys(inner); got != "action,multiSigUser,outerSigner" {
+			t.Fatalf("payload keys = %s", got)
+		}
+		if inner["multiSigUser"] != strings.ToLower(multiSigUser) || inner["outerSigner"] != strings.ToLower(outer.Address().Hex()) {
+			t.Errorf("multiSigUser = %v, outerSigner = %v", inner["multiSigUser"], inner["outerSigner"])
+		}
+		if inner["action"].(map[string]interface{})["type"] != "order" {
+			t.Errorf("inner action = %v", inner["action"])
+		}
+
+		want, err := SignMultiSigAction(outer, signed.Action.(MultiSigAction), false, "", fixtureNonce)
+		if err != nil {
+			t.Fatalf("SignMultiSigAction: %v", err)
+		}
+		if signed.Signature != want {
+			t.Errorf("signature = %+v, want outer signer's %+v", signed.Signature, want)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		signers []Wallet
+		outer   Wallet
+	}{
+		{"below threshold", []Wallet{outer}, outer},
+		{"duplicate signer", []Wallet{outer, outer}, outer},
+		{"unauthorized signer", []Wallet{outer, stranger}, outer},
+		{"unauthorized outer signer", []Wallet{outer, cosigner}, stranger},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildMultiSigOrderEnvelope(orders, GroupingNA, tt.signers, config, tt.outer, "", fixtureNonce, false); err == nil {
+				t.Fatal("no error")
+			}
+		})
+	}
+}
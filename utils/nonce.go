@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+)
+
+// NonceManager issues strictly increasing millisecond nonces. When the clock has
+// not advanced past the last issued nonce, the next nonce is bumped by one.
+// It is safe for concurrent use
+type NonceManager struct {
+	mu   sync.Mutex
+	last uint64
+}
+
+func NewNonceManager() *NonceManager {
+	return &NonceManager{}
+}
+
+// Next returns a nonce greater than every nonce previously returned by m
+func (m *NonceManager) Next() uint64 {
+	now := uint64(GetTimestampMs())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now <= m.last {
+		now = m.last + 1
+	}
+	m.last = now
+
+	return now
+}
+
+// KeyedNonceManager issues strictly increasing nonces per (signer, vault address)
+// pair, so submissions to different vaults never push each other's nonces up.
+// It is safe for concurrent use
+type KeyedNonceManager struct {
+	mu       sync.Mutex
+	managers map[nonceKey]*NonceManager
+}
+
+type nonceKey struct {
+	signer string
+	vault  string
+}
+
+func NewKeyedNonceManager() *KeyedNonceManager {
+	return &KeyedNonceManager{managers: make(map[nonceKey]*NonceManager)}
+}
+
+// Next returns a nonce greater than every nonce previously returned for signer and
+// vaultAddress. An empty vaultAddress is the signer's own account
+func (m *KeyedNonceManager) Next(signer string, vaultAddress string) uint64 {
+	return m.ForKey(signer, vaultAddress).Next()
+}
+
+// ForKey returns the NonceManager backing signer and vaultAddress, e.g. to hand to a
+// client that always signs for the same pair
+func (m *KeyedNonceManager) ForKey(signer string, vaultAddress string) *NonceManager {
+	key := nonceKey{signer: strings.ToLower(signer), vault: strings.ToLower(vaultAddress)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	manager, ok := m.managers[key]
+	if !ok {
+		manager = NewNonceManager()
+		m.managers[key] = manager
+	}
+	return manager
+}
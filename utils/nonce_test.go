@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNonceManagerConcurrentStrictlyIncreasing(t *testing.T) {
+	const (
+		goroutines = 32
+		perRoutine = 2000
+	)
+
+	m := NewNonceManager()
+	results := make([][]uint64, goroutines)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			nonces := make([]uint64, perRoutine)
+			for i := range nonces {
+				nonces[i] = m.Next()
+			}
+			results[g] = nonces
+		}(g)
+	}
+	wg.Wait()
+
+	all := make([]uint64, 0, goroutines*perRoutine)
+	for g, nonces := range results {
+		for i := 1; i < len(nonces); i++ {
+			if nonces[i] <= nonces[i-1] {
+				t.Fatalf("goroutine %d: nonce %d = %d not greater than previous %d", g, i, nonces[i], nonces[i-1])
+			}
+		}
+		all = append(all, nonces...)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	for i := 1; i < len(all); i++ {
+		if all[i] == all[i-1] {
+			t.Fatalf("nonce %d issued twice", all[i])
+		}
+	}
+
+	if next := m.Next(); next <= all[len(all)-1] {
+		t.Fatalf("Next after hammer = %d, want > %d", next, all[len(all)-1])
+	}
+}
+
+func TestKeyedNonceManagerTwoVaultsOneSigner(t *testing.T) {
+	const (
+		signer     = "0x14791697260E4c9A71f18484C9f997B308e59325"
+		goroutines = 8
+		perRoutine = 1000
+	)
+	vaults := []string{
+		"0x1719884eb866cb12b2287399b15f7db5e7d775ea",
+		"0x0000000000000000000000000000000000000001",
+	}
+
+	m := NewKeyedNonceManager()
+	results := make([][][]uint64, len(vaults))
+	for v := range vaults {
+		results[v] = make([][]uint64, goroutines)
+	}
+
+	var wg sync.WaitGroup
+	for v, vault := range vaults {
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(v, g int, vault string) {
+				defer wg.Done()
+				nonces := make([]uint64, perRoutine)
+				for i := range nonces {
+					nonces[i] = m.Next(signer, vault)
+				}
+				results[v][g] = nonces
+			}(v, g, vault)
+		}
+	}
+	wg.Wait()
+
+	for v, vault := range vaults {
+		seen := make(map[uint64]bool, goroutines*perRoutine)
+		var last uint64
+		for g, nonces := range results[v] {
+			for i, n := range nonces {
+				if i > 0 && n <= nonces[i-1] {
+					t.Fatalf("vault %s goroutine %d: nonce %d not greater than %d", vault, g, n, nonces[i-1])
+				}
+				if seen[n] {
+					t.Fatalf("vault %s: nonce %d issued twice", vault, n)
+				}
+				seen[n] = true
+				last = max(last, n)
+			}
+		}
+		if next := m.Next(strings.ToUpper(signer), strings.ToUpper(vault)); next <= last {
+			t.Errorf("vault %s: Next = %d, want > %d", vault, next, last)
+		}
+	}
+
+	if m.ForKey(signer, vaults[0]) == m.ForKey(signer, vaults[1]) {
+		t.Error("vaults share a NonceManager")
+	}
+	if m.ForKey(signer, vaults[0]) == m.ForKey(signer, "") {
+		t.Error("vault shares the signer's own NonceManager")
+	}
+}
@@ -1,22 +1,34 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"time"
 )
 
+// DefaultSlippage is the default fraction market orders may move away from the reference price
+const DefaultSlippage = 0.05
+
 func OrderTypeToWire(orderType OrderType) (OrderTypeWire, error) {
 	var result OrderTypeWire
 
 	if orderType.Limit != nil {
+		if !orderType.Limit.TIF.Valid() {
+			return OrderTypeWire{}, fmt.Errorf("invalid tif %q", orderType.Limit.TIF)
+		}
 		result.Limit = orderType.Limit
 		return result, nil
 	}
 
 	if orderType.Trigger != nil {
+		if !orderType.Trigger.TPSL.Valid() {
+			return OrderTypeWire{}, fmt.Errorf("%w %q", ErrInvalidTPSL, orderType.Trigger.TPSL)
+		}
+
 		wirePrice, err := FloatToWire(orderType.Trigger.TriggerPx)
 		if err != nil {
-			return OrderTypeWire{}, fmt.Errorf("converting trigger price: %w", err)
+			return OrderTypeWire{}, &WireError{Index: -1, Field: "trigger price", Value: orderType.Trigger.TriggerPx, Err: err}
 		}
 
 		result.Trigger = &TriggerOrderTypeWire{
@@ -37,17 +49,21 @@ func OrderRequestToOrderWire(order OrderRequest, asset int) (OrderWire, error) {
 
 	wireType, err := OrderTypeToWire(order.OrderType)
 	if err != nil {
+		var wireErr *WireError
+		if errors.As(err, &wireErr) {
+			return OrderWire{}, err
+		}
 		return OrderWire{}, fmt.Errorf("converting order type: %w", err)
 	}
 
 	wirePrice, err := FloatToWire(order.LimitPrice)
 	if err != nil {
-		return OrderWire{}, fmt.Errorf("converting limit price: %w", err)
+		return OrderWire{}, &WireError{Index: -1, Field: "limit price", Value: order.LimitPrice, Err: err}
 	}
 
 	wireSize, err := FloatToWire(order.Size)
 	if err != nil {
-		return OrderWire{}, fmt.Errorf("converting size: %w", err)
+		return OrderWire{}, &WireError{Index: -1, Field: "size", Value: order.Size, Err: err}
 	}
 
 	orderWire := OrderWire{
@@ -67,6 +83,74 @@ func OrderRequestToOrderWire(order OrderRequest, asset int) (OrderWire, error) {
 	return orderWire, nil
 }
 
+// OrderRequestToOrderWireWithRegistry rounds the order's prices and size to the
+// asset's precision before converting it. The caller's order is not modified
+func OrderRequestToOrderWireWithRegistry(order OrderRequest, reg *AssetRegistry) (OrderWire, error) {
+	info, ok := reg.Lookup(order.Coin)
+	if !ok {
+		return OrderWire{}, fmt.Errorf("unknown asset: %s", order.Coin)
+	}
+
+	rounded := order
+	rounded.LimitPrice = info.RoundPrice(order.LimitPrice)
+	rounded.Size = info.RoundSize(order.Size)
+	if order.OrderType.Trigger != nil {
+		trigger := *order.OrderType.Trigger
+		trigger.TriggerPx = info.RoundPrice(trigger.TriggerPx)
+		rounded.OrderType.Trigger = &trigger
+	}
+
+	return OrderRequestToOrderWire(rounded, info.Index)
+}
+
+// ValidateBatchPrecision reports every price or size in orders that is finer than
+// its asset allows, without modifying anything. Each *WireError carries the order's
+// index and field and wraps a *PrecisionError whose Rounded value would pass. The
+// result is empty when the whole batch is valid
+func ValidateBatchPrecision(orders []OrderRequest, reg *AssetRegistry) []*WireError {
+	var problems []*WireError
+
+	for i, order := range orders {
+		info, ok := reg.Lookup(order.Coin)
+		if !ok {
+			problems = append(problems, &WireError{Index: i, Field: "coin", Err: fmt.Errorf("unknown asset: %s", order.Coin)})
+			continue
+		}
+
+		if rounded := info.RoundPrice(order.LimitPrice); rounded != order.LimitPrice {
+			problems = append(problems, &WireError{Index: i, Field: "limit price", Value: order.LimitPrice,
+				Err: &PrecisionError{Original: order.LimitPrice, Rounded: rounded, Places: priceDecimalsFor(info, order.LimitPrice)}})
+		}
+
+		if rounded := info.RoundSize(order.Size); rounded != order.Size {
+			problems = append(problems, &WireError{Index: i, Field: "size", Value: order.Size,
+				Err: &PrecisionError{Original: order.Size, Rounded: rounded, Places: info.SzDecimals}})
+		}
+
+		if trigger := order.OrderType.Trigger; trigger != nil {
+			if rounded := info.RoundPrice(trigger.TriggerPx); rounded != trigger.TriggerPx {
+				problems = append(problems, &WireError{Index: i, Field: "trigger price", Value: trigger.TriggerPx,
+					Err: &PrecisionError{Original: trigger.TriggerPx, Rounded: rounded, Places: priceDecimalsFor(info, trigger.TriggerPx)}})
+			}
+		}
+	}
+
+	return problems
+}
+
+// priceDecimalsFor returns the decimals allowed for px, which the significant
+// figure limit can make stricter than the asset's price decimals
+func priceDecimalsFor(info AssetInfo, px float64) int {
+	places := info.PriceDecimals()
+	if abs := math.Abs(px); abs >= 1 {
+		intDigits := int(math.Floor(math.Log10(abs))) + 1
+		if bySigFigs := MaxPriceSigFigs - intDigits; bySigFigs < places {
+			places = max(bySigFigs, 0)
+		}
+	}
+	return places
+}
+
 func OrderWiresToOrderAction(orderWires []OrderWire, builder string) OrderAction {
 	return OrderAction{
 		Type:     "order",
@@ -76,24 +160,104 @@ func OrderWiresToOrderAction(orderWires []OrderWire, builder string) OrderAction
 	}
 }
 
+// OrderWiresToOrderActionGrouped is OrderWiresToOrderAction with an explicit
+// grouping, which must be one of the GroupingType constants
+func OrderWiresToOrderActionGrouped(orderWires []OrderWire, grouping GroupingType, builder string) (OrderAction, error) {
+	if !grouping.Valid() {
+		return OrderAction{}, fmt.Errorf("invalid grouping %q", grouping)
+	}
+
+	action := OrderWiresToOrderAction(orderWires, builder)
+	action.Grouping = grouping
+	return action, nil
+}
+
+// DefaultMaxOrdersPerBatch is the chunk size ChunkOrderWires uses when maxPerBatch
+// is not positive
+const DefaultMaxOrdersPerBatch = 1000
+
+// ChunkOrderWires splits orders into consecutive batches of at most maxPerBatch
+// orders. The chunks share orders' backing array
+func ChunkOrderWires(orders []OrderWire, maxPerBatch int) [][]OrderWire {
+	if maxPerBatch <= 0 {
+		maxPerBatch = DefaultMaxOrdersPerBatch
+	}
+
+	chunks := make([][]OrderWire, 0, (len(orders)+maxPerBatch-1)/maxPerBatch)
+	for start := 0; start < len(orders); start += maxPerBatch {
+		end := start + maxPerBatch
+		if end > len(orders) {
+			end = len(orders)
+		}
+		chunks = append(chunks, orders[start:end:end])
+	}
+	return chunks
+}
+
+// SignChunkedOrderActions splits orders with ChunkOrderWires and signs one order
+// action per chunk, each with its own nonce from nonces. Only GroupingNA batches can
+// be split: TP/SL groupings must stay within a single action
+func SignChunkedOrderActions(
+	wallet Wallet,
+	orders []OrderWire,
+	grouping GroupingType,
+	builder string,
+	vaultAddress string,
+	maxPerBatch int,
+	nonces *NonceManager,
+	isMainnet bool,
+) ([]SignedAction, error) {
+	chunks := ChunkOrderWires(orders, maxPerBatch)
+	if len(chunks) > 1 && grouping != GroupingNA {
+		return nil, fmt.Errorf("cannot split a %s batch of %d orders into %d actions", grouping, len(orders), len(chunks))
+	}
+
+	signed := make([]SignedAction, 0, len(chunks))
+	for i, chunk := range chunks {
+		action, err := OrderWiresToOrderActionGrouped(chunk, grouping, builder)
+		if err != nil {
+			return nil, err
+		}
+
+		signedAction, err := NewSignedOrderAction(wallet, action, vaultAddress, nonces.Next(), isMainnet)
+		if err != nil {
+			return nil, fmt.Errorf("signing chunk %d: %w", i, err)
+		}
+		signed = append(signed, signedAction)
+	}
+
+	return signed, nil
+}
+
 func GetTimestampMs() int64 {
 	return time.Now().UnixNano() / int64(time.Millisecond)
 }
 
+// BatchOrdersToWire converts a batch of orders, resolving each coin with
+// LookupAsset: tickers are looked up in assetMap, while spot pairs may also be given
+// as "@N" (pair index N), which needs no assetMap entry
 func BatchOrdersToWire(orders []OrderRequest, assetMap map[string]int) ([]OrderWire, error) {
 	if len(orders) == 0 {
 		return nil, fmt.Errorf("no orders provided")
 	}
 
+	if err := CheckDuplicateCloids(orders); err != nil {
+		return nil, err
+	}
+
 	wireOrders := make([]OrderWire, 0, len(orders))
-	for _, order := range orders {
-		asset, ok := assetMap[order.Coin]
+	for i, order := range orders {
+		asset, ok := LookupAsset(assetMap, order.Coin)
 		if !ok {
 			return nil, fmt.Errorf("unknown asset: %s", order.Coin)
 		}
 
 		wireOrder, err := OrderRequestToOrderWire(order, asset)
 		if err != nil {
+			var wireErr *WireError
+			if errors.As(err, &wireErr) {
+				wireErr.Index = i
+			}
 			return nil, fmt.Errorf("converting order for %s: %w", order.Coin, err)
 		}
 
@@ -103,6 +267,50 @@ func BatchOrdersToWire(orders []OrderRequest, assetMap map[string]int) ([]OrderW
 	return wireOrders, nil
 }
 
+// CheckDuplicateCloids returns an error naming the first cloid shared by two orders
+// of the batch; the exchange rejects the whole batch in that case
+func CheckDuplicateCloids(orders []OrderRequest) error {
+	seen := make(map[Cloid]int, len(orders))
+	for i, order := range orders {
+		if order.Cloid == nil {
+			continue
+		}
+		cloid := order.Cloid.Normalize()
+		if first, ok := seen[cloid]; ok {
+			return fmt.Errorf("%w: %s used by orders %d and %d", ErrDuplicateCloid, *order.Cloid, first, i)
+		}
+		seen[cloid] = i
+	}
+	return nil
+}
+
+// BatchOrdersToWireWithCloids is BatchOrdersToWire with a random cloid generated for
+// every order that has none. It returns the cloid of each order, index-aligned with
+// orders, so a retry can resubmit the same cloids and be deduplicated by the exchange.
+// orders is not modified
+func BatchOrdersToWireWithCloids(orders []OrderRequest, assetMap map[string]int) ([]OrderWire, []Cloid, error) {
+	withCloids := make([]OrderRequest, len(orders))
+	cloids := make([]Cloid, len(orders))
+	for i, order := range orders {
+		if order.Cloid == nil {
+			cloid, err := GenerateRandomCloid()
+			if err != nil {
+				return nil, nil, err
+			}
+			order.Cloid = &cloid
+		}
+		withCloids[i] = order
+		cloids[i] = *order.Cloid
+	}
+
+	wireOrders, err := BatchOrdersToWire(withCloids, assetMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wireOrders, cloids, nil
+}
+
 func CreateLimitOrderType(tif TIF) LimitOrderType {
 	return LimitOrderType{TIF: tif}
 }
@@ -137,6 +345,75 @@ func CreateLimitOrder(
 	}
 }
 
+// CreateLimitOrderSide is CreateLimitOrder taking the order side as a Side
+func CreateLimitOrderSide(coin string, side Side, size, price float64, tif TIF, reduceOnly bool, cloid *Cloid) OrderRequest {
+	return CreateLimitOrder(coin, side.IsBuy(), size, price, tif, reduceOnly, cloid)
+}
+
+// CreateIOCOrder creates an immediate-or-cancel limit order, the building block of
+// market orders: price acts as the worst acceptable fill price. The order is
+// validated as it is built
+func CreateIOCOrder(coin string, isBuy bool, size, price float64, reduceOnly bool, cloid *Cloid) (OrderRequest, error) {
+	return newTIFOrder(coin, isBuy, size, price, TIFIoc, false, reduceOnly, cloid)
+}
+
+// CreateALOOrder creates a post-only (add liquidity only) limit order, which the
+// exchange cancels instead of letting it take liquidity. The order is validated as
+// it is built
+func CreateALOOrder(coin string, isBuy bool, size, price float64, reduceOnly bool, cloid *Cloid) (OrderRequest, error) {
+	return newTIFOrder(coin, isBuy, size, price, TIFAlo, false, reduceOnly, cloid)
+}
+
+// newTIFOrder builds and validates a limit order. A market order must fill
+// immediately, which only IOC does: ALO would be cancelled instead of taking
+// liquidity and GTC would rest on the book
+func newTIFOrder(coin string, isBuy bool, size, price float64, tif TIF, isMarket, reduceOnly bool, cloid *Cloid) (OrderRequest, error) {
+	if isMarket && tif != TIFIoc {
+		return OrderRequest{}, fmt.Errorf("%w: market orders must be %s, got %s", ErrIncompatibleTIF, TIFIoc, tif)
+	}
+
+	order := CreateLimitOrder(coin, isBuy, size, price, tif, reduceOnly, cloid)
+	if err := order.Validate(); err != nil {
+		return OrderRequest{}, fmt.Errorf("invalid %s order: %w", tif, err)
+	}
+	return order, nil
+}
+
+// SlippagePrice returns refPrice moved against the taker by slippage (a fraction,
+// e.g. 0.05 for 5%), which is the limit price of a market order
+func SlippagePrice(refPrice float64, isBuy bool, slippage float64) float64 {
+	if isBuy {
+		return refPrice * (1 + slippage)
+	}
+	return refPrice * (1 - slippage)
+}
+
+// CreateMarketOrder creates an IOC order priced at refPrice adjusted by slippage
+func CreateMarketOrder(coin string, isBuy bool, size, refPrice, slippage float64, reduceOnly bool, cloid *Cloid) (OrderRequest, error) {
+	return newTIFOrder(coin, isBuy, size, SlippagePrice(refPrice, isBuy, slippage), TIFIoc, true, reduceOnly, cloid)
+}
+
+// CreateMarketOrderSide is CreateMarketOrder taking the order side as a Side
+func CreateMarketOrderSide(coin string, side Side, size, refPrice, slippage float64, reduceOnly bool, cloid *Cloid) (OrderRequest, error) {
+	return CreateMarketOrder(coin, side.IsBuy(), size, refPrice, slippage, reduceOnly, cloid)
+}
+
+// CreateCloseOrder creates a reduce-only order closing currentPositionSize: a long
+// (positive) position is closed by selling, a short (negative) one by buying
+func CreateCloseOrder(coin string, currentPositionSize float64, price float64, tif TIF, cloid *Cloid) OrderRequest {
+	return CreateLimitOrder(coin, currentPositionSize < 0, math.Abs(currentPositionSize), price, tif, true, cloid)
+}
+
+// CreateMarketCloseOrder is the market variant of CreateCloseOrder, priced at
+// refPrice adjusted by slippage
+func CreateMarketCloseOrder(coin string, currentPositionSize float64, refPrice, slippage float64, cloid *Cloid) (OrderRequest, error) {
+	isBuy := currentPositionSize < 0
+	return newTIFOrder(coin, isBuy, math.Abs(currentPositionSize), SlippagePrice(refPrice, isBuy, slippage), TIFIoc, true, true, cloid)
+}
+
+// CreateTriggerOrder creates a trigger order. tpsl must be TPSLTakeProfit or
+// TPSLStopLoss; the rest of the order is checked by OrderRequest.Validate when the
+// order is converted to its wire form
 func CreateTriggerOrder(
 	coin string,
 	isBuy bool,
@@ -147,7 +424,11 @@ func CreateTriggerOrder(
 	tpsl TPSL,
 	reduceOnly bool,
 	cloid *Cloid,
-) OrderRequest {
+) (OrderRequest, error) {
+	if !tpsl.Valid() {
+		return OrderRequest{}, fmt.Errorf("%w %q: must be %s or %s", ErrInvalidTPSL, tpsl, TPSLTakeProfit, TPSLStopLoss)
+	}
+
 	return OrderRequest{
 		Coin:       coin,
 		IsBuy:      isBuy,
@@ -162,7 +443,51 @@ func CreateTriggerOrder(
 		},
 		ReduceOnly: reduceOnly,
 		Cloid:      cloid,
+	}, nil
+}
+
+// CreatePositionTPSL creates take-profit and/or stop-loss orders attached to an
+// existing position (isBuy is true for a long position) with GroupingPositionTPSL.
+// The orders are reduce-only, on the side opposite the position, and have a zero
+// size, which the exchange resolves to the whole position. Market triggers are
+// priced with DefaultSlippage around the trigger price, limit triggers at it
+func CreatePositionTPSL(coin string, isBuy bool, tp, sl *TriggerOrderType) ([]OrderRequest, GroupingType, error) {
+	if tp == nil && sl == nil {
+		return nil, "", errors.New("at least one of take profit or stop loss must be provided")
+	}
+	if tp != nil && sl != nil && tp.IsMarket != sl.IsMarket {
+		return nil, "", errors.New("take profit and stop loss must both be market or both be limit triggers")
+	}
+
+	closeIsBuy := !isBuy
+	orders := make([]OrderRequest, 0, 2)
+	for _, leg := range []struct {
+		trigger *TriggerOrderType
+		tpsl    TPSL
+	}{{tp, TPSLTakeProfit}, {sl, TPSLStopLoss}} {
+		if leg.trigger == nil {
+			continue
+		}
+		if leg.trigger.TPSL != "" && leg.trigger.TPSL != leg.tpsl {
+			return nil, "", fmt.Errorf("trigger passed as %s has tpsl %q", leg.tpsl, leg.trigger.TPSL)
+		}
+
+		limitPrice := leg.trigger.TriggerPx
+		if leg.trigger.IsMarket {
+			limitPrice = SlippagePrice(leg.trigger.TriggerPx, closeIsBuy, DefaultSlippage)
+		}
+
+		order, err := CreateTriggerOrder(coin, closeIsBuy, 0, limitPrice, leg.trigger.TriggerPx, leg.trigger.IsMarket, leg.tpsl, true, nil)
+		if err == nil {
+			err = order.Validate()
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s order: %w", leg.tpsl, err)
+		}
+		orders = append(orders, order)
 	}
+
+	return orders, GroupingPositionTPSL, nil
 }
 
 func CreateModifyRequest(orderID int64, newOrder OrderRequest) ModifyRequest {
@@ -185,7 +510,7 @@ func ModifyRequestToWire(req ModifyRequest, assetMap map[string]int) (ModifyWire
 		return ModifyWire{}, fmt.Errorf("invalid modify request: %w", err)
 	}
 
-	asset, ok := assetMap[req.Order.Coin]
+	asset, ok := LookupAsset(assetMap, req.Order.Coin)
 	if !ok {
 		return ModifyWire{}, fmt.Errorf("unknown asset: %s", req.Order.Coin)
 	}
@@ -204,3 +529,161 @@ func ModifyRequestToWire(req ModifyRequest, assetMap map[string]int) (ModifyWire
 		Order:   wireOrder,
 	}, nil
 }
+
+func CreateCancelAction(cancels []CancelRequest, assetMap map[string]int) (CancelAction, error) {
+	if len(cancels) == 0 {
+		return CancelAction{}, fmt.Errorf("no cancels provided")
+	}
+
+	wires := make([]CancelWire, 0, len(cancels))
+	for _, cancel := range cancels {
+		if err := cancel.Validate(); err != nil {
+			return CancelAction{}, fmt.Errorf("invalid cancel request: %w", err)
+		}
+
+		asset, ok := LookupAsset(assetMap, cancel.Coin)
+		if !ok {
+			return CancelAction{}, fmt.Errorf("unknown asset: %s", cancel.Coin)
+		}
+
+		wires = append(wires, CancelWire{Asset: asset, OrderID: cancel.OrderID})
+	}
+
+	return CancelAction{Type: "cancel", Cancels: wires}, nil
+}
+
+func CreateCancelByCloidAction(cancels []CancelByCloidRequest, assetMap map[string]int) (CancelByCloidAction, error) {
+	if len(cancels) == 0 {
+		return CancelByCloidAction{}, fmt.Errorf("no cancels provided")
+	}
+
+	wires := make([]CancelByCloidWire, 0, len(cancels))
+	for _, cancel := range cancels {
+		if err := cancel.Validate(); err != nil {
+			return CancelByCloidAction{}, fmt.Errorf("invalid cancel request: %w", err)
+		}
+
+		asset, ok := LookupAsset(assetMap, cancel.Coin)
+		if !ok {
+			return CancelByCloidAction{}, fmt.Errorf("unknown asset: %s", cancel.Coin)
+		}
+
+		wires = append(wires, CancelByCloidWire{Asset: asset, Cloid: cancel.Cloid.ToRaw()})
+	}
+
+	return CancelByCloidAction{Type: "cancelByCloid", Cancels: wires}, nil
+}
+
+// CancelSmart builds the cancel action for a single order, preferring its cloid:
+// a CancelByCloidAction when cloid is set, otherwise a CancelAction by oid. The
+// result is ready for SignL1Action
+func CancelSmart(coin string, oid int64, cloid *Cloid, assetMap map[string]int) (interface{}, error) {
+	if cloid != nil {
+		action, err := CreateCancelByCloidAction([]CancelByCloidRequest{{Coin: coin, Cloid: *cloid}}, assetMap)
+		if err != nil {
+			return nil, err
+		}
+		return action, nil
+	}
+	if oid <= 0 {
+		return nil, fmt.Errorf("cancelling %s: either oid or cloid must be specified", coin)
+	}
+
+	action, err := CreateCancelAction([]CancelRequest{{Coin: coin, OrderID: oid}}, assetMap)
+	if err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+func CreateBatchModifyAction(modifies []ModifyRequest, assetMap map[string]int) (BatchModifyAction, error) {
+	if len(modifies) == 0 {
+		return BatchModifyAction{}, fmt.Errorf("no modifies provided")
+	}
+
+	wires := make([]ModifyWire, 0, len(modifies))
+	for _, modify := range modifies {
+		wire, err := ModifyRequestToWire(modify, assetMap)
+		if err != nil {
+			return BatchModifyAction{}, err
+		}
+		wires = append(wires, wire)
+	}
+
+	return BatchModifyAction{Type: "batchModify", Modifies: wires}, nil
+}
+
+func CreateUpdateLeverageAction(asset int, isCross bool, leverage int) (UpdateLeverageAction, error) {
+	if leverage <= 0 {
+		return UpdateLeverageAction{}, fmt.Errorf("leverage must be positive")
+	}
+
+	return UpdateLeverageAction{
+		Type:     "updateLeverage",
+		Asset:    asset,
+		IsCross:  isCross,
+		Leverage: leverage,
+	}, nil
+}
+
+// CreateUpdateLeverageActionValidated is CreateUpdateLeverageAction for coin that
+// also rejects a leverage above the asset's maxLeverage, and cross margin on an
+// isolated-only asset, before the exchange would
+func CreateUpdateLeverageActionValidated(coin string, isCross bool, leverage int, meta Meta) (UpdateLeverageAction, error) {
+	asset, ok := LookupAsset(meta.AssetMap(), coin)
+	if !ok || asset >= len(meta.Universe) {
+		return UpdateLeverageAction{}, fmt.Errorf("unknown asset: %s", coin)
+	}
+
+	info := meta.Universe[asset]
+	if info.MaxLeverage > 0 && leverage > info.MaxLeverage {
+		return UpdateLeverageAction{}, fmt.Errorf("leverage %d exceeds the maximum of %d for %s", leverage, info.MaxLeverage, info.Name)
+	}
+	if isCross && info.OnlyIsolated {
+		return UpdateLeverageAction{}, fmt.Errorf("%s only supports isolated margin", info.Name)
+	}
+
+	return CreateUpdateLeverageAction(asset, isCross, leverage)
+}
+
+// CreateTwapCancelAction builds the twapCancel action for the TWAP twapID on asset;
+// sign it with SignL1Action
+func CreateTwapCancelAction(asset int, twapID int64) (TwapCancelAction, error) {
+	if twapID <= 0 {
+		return TwapCancelAction{}, fmt.Errorf("twap ID must be positive")
+	}
+
+	return TwapCancelAction{
+		Type:   "twapCancel",
+		Asset:  asset,
+		TwapID: twapID,
+	}, nil
+}
+
+// CreateTwapCancelActionForCoin is CreateTwapCancelAction resolving coin through assetMap
+func CreateTwapCancelActionForCoin(coin string, twapID int64, assetMap map[string]int) (TwapCancelAction, error) {
+	asset, ok := LookupAsset(assetMap, coin)
+	if !ok {
+		return TwapCancelAction{}, fmt.Errorf("unknown asset: %s", coin)
+	}
+
+	return CreateTwapCancelAction(asset, twapID)
+}
+
+// CreateSpotUserAction builds the spotUser action opting the account out of (or
+// back into) spot dusting; sign it with SignL1Action
+func CreateSpotUserAction(optOut bool) SpotUserAction {
+	return SpotUserAction{
+		Type:              "spotUser",
+		ToggleSpotDusting: SpotDustingToggle{OptOut: optOut},
+	}
+}
+
+// CreateEvmUserModifyAction builds the evmUserModify action choosing big or small
+// HyperEVM blocks; sign it with SignL1Action
+func CreateEvmUserModifyAction(usingBigBlocks bool) EvmUserModifyAction {
+	return EvmUserModifyAction{
+		Type:           "evmUserModify",
+		UsingBigBlocks: usingBigBlocks,
+	}
+}
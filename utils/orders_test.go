@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// fixtureOrderAction is the reference SDK's test order: buy 0.0147 ETH (asset 4)
+// at 1670.1, IOC, no cloid
+func fixtureOrderAction(t testing.TB, cloid *Cloid) OrderAction {
+	t.Helper()
+	wire, err := OrderRequestToOrderWire(CreateLimitOrder("ETH", true, 0.0147, 1670.1, TIFIoc, false, cloid), 4)
+	if err != nil {
+		t.Fatalf("OrderRequestToOrderWire: %v", err)
+	}
+	return OrderWiresToOrderAction([]OrderWire{wire}, "")
+}
+
+func TestOrderActionHashMatchesReference(t *testing.T) {
+	// connectionId the reference SDK computes for this order at fixtureNonce
+	const want = "0x0fcbeda5ae3c4950a548021552a4fea2226858c4453571bf3f24ba017eac2908"
+
+	got, err := ActionConnectionID(fixtureOrderAction(t, nil), "", fixtureNonce)
+	if err != nil {
+		t.Fatalf("ActionConnectionID: %v", err)
+	}
+	if got != want {
+		t.Errorf("connectionId = %s, want %s", got, want)
+	}
+}
+
+func TestOrderActionBytesWithAndWithoutCloid(t *testing.T) {
+	cloid := Cloid("0x00000000000000000000000000000001")
+	tests := []struct {
+		name  string
+		cloid *Cloid
+		want  string
+	}{
+		{
+			name: "without cloid",
+			want: "0x83a474797065a56f72646572a66f72646572739186a16104a162c3a170a6313637302e31a173a6302e30313437a172c2a17481a56c696d697481a3746966a3496f63a867726f7570696e67a26e61",
+		},
+		{
+			name:  "with cloid",
+			cloid: &cloid,
+			want:  "0x83a474797065a56f72646572a66f72646572739187a16104a162c3a170a6313637302e31a173a6302e30313437a172c2a17481a56c696d697481a3746966a3496f63a163d92230783030303030303030303030303030303030303030303030303030303030303031a867726f7570696e67a26e61",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalAction(fixtureOrderAction(t, tt.cloid))
+			if err != nil {
+				t.Fatalf("MarshalAction: %v", err)
+			}
+			if hexutil.Encode(got) != tt.want {
+				t.Errorf("bytes\n got %s\nwant %s", hexutil.Encode(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSignOrderWithoutCloid(t *testing.T) {
+	wallet := testWallet(t)
+	action := fixtureOrderAction(t, nil)
+
+	data, err := MarshalAction(action)
+	if err != nil {
+		t.Fatalf("MarshalAction: %v", err)
+	}
+	var decoded struct {
+		Orders []map[string]interface{} `msgpack:"orders"`
+	}
+	if err := msgpack.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decoding action: %v", err)
+	}
+	if len(decoded.Orders) != 1 {
+		t.Fatalf("decoded %d orders, want 1", len(decoded.Orders))
+	}
+	if c, ok := decoded.Orders[0]["c"]; ok {
+		t.Errorf("order without cloid encodes c = %v", c)
+	}
+
+	sig, err := SignOrderAction(wallet, action, "", fixtureNonce, true)
+	if err != nil {
+		t.Fatalf("SignOrderAction: %v", err)
+	}
+	encoded, err := encodeL1TypedData(action, "", fixtureNonce, nil, true)
+	if err != nil {
+		t.Fatalf("encodeL1TypedData: %v", err)
+	}
+	ok, err := VerifySignature(wallet.Address().Hex(), encoded, sig)
+	if err != nil || !ok {
+		t.Errorf("VerifySignature = %v, %v", ok, err)
+	}
+}
+
+func BenchmarkSignOrderAction(b *testing.B) {
+	wallet := testWallet(b)
+	action := fixtureOrderAction(b, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := SignOrderAction(wallet, action, "", fixtureNonce, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestOrderConstructorsRejectInvalidCombinations(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() (OrderRequest, error)
+		wantErr error
+	}{
+		{"ALO market order", func() (OrderRequest, error) {
+			return newTIFOrder("ETH", true, 1, 1670.1, TIFAlo, true, false, nil)
+		}, ErrIncompatibleTIF},
+		{"GTC market order", func() (OrderRequest, error) {
+			return newTIFOrder("ETH", true, 1, 1670.1, TIFGtc, true, false, nil)
+		}, ErrIncompatibleTIF},
+		{"invalid TIF", func() (OrderRequest, error) {
+			return newTIFOrder("ETH", true, 1, 1670.1, TIF("Fok"), false, false, nil)
+		}, nil},
+		{"IOC zero size", func() (OrderRequest, error) {
+			return CreateIOCOrder("ETH", true, 0, 1670.1, false, nil)
+		}, nil},
+		{"ALO non-positive price", func() (OrderRequest, error) {
+			return CreateALOOrder("ETH", false, 1, 0, false, nil)
+		}, nil},
+		{"market order from non-positive reference price", func() (OrderRequest, error) {
+			return CreateMarketOrder("ETH", true, 1, 0, DefaultSlippage, false, nil)
+		}, nil},
+		{"market close of a flat position", func() (OrderRequest, error) {
+			return CreateMarketCloseOrder("ETH", 0, 1670.1, DefaultSlippage, nil)
+		}, nil},
+		{"trigger with empty tpsl", func() (OrderRequest, error) {
+			return CreateTriggerOrder("BTC", false, 0.01, 60000, 61000.5, true, "", true, nil)
+		}, ErrInvalidTPSL},
+		{"trigger with unknown tpsl", func() (OrderRequest, error) {
+			return CreateTriggerOrder("BTC", false, 0.01, 60000, 61000.5, true, TPSL("SL"), true, nil)
+		}, ErrInvalidTPSL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := tt.build()
+			if err == nil {
+				t.Fatalf("built %+v, want error", order)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrderConstructorsTIF(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() (OrderRequest, error)
+		want  TIF
+	}{
+		{"IOC", func() (OrderRequest, error) { return CreateIOCOrder("ETH", true, 1, 1670.1, false, nil) }, TIFIoc},
+		{"ALO", func() (OrderRequest, error) { return CreateALOOrder("ETH", true, 1, 1670.1, false, nil) }, TIFAlo},
+		{"market", func() (OrderRequest, error) {
+			return CreateMarketOrder("ETH", true, 1, 1670.1, DefaultSlippage, false, nil)
+		}, TIFIoc},
+		{"market close", func() (OrderRequest, error) { return CreateMarketCloseOrder("ETH", -2, 1670.1, DefaultSlippage, nil) }, TIFIoc},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := tt.build()
+			if err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			if order.OrderType.Limit == nil || order.OrderType.Limit.TIF != tt.want {
+				t.Errorf("order type = %+v, want limit %s", order.OrderType, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrUnknownActionType = errors.New("unknown user-signed action type")
+
+// UserSignedActionType describes how a user-signed action is encoded for EIP-712
+type UserSignedActionType struct {
+	PayloadTypes []SignatureType
+	PrimaryType  string
+}
+
+// userSignedActionTypes maps exchange action type names to their EIP-712 encoding
+var userSignedActionTypes = struct {
+	sync.RWMutex
+	types map[string]UserSignedActionType
+}{types: map[string]UserSignedActionType{
+	"usdSend":               {USDSendSignTypes, "HyperliquidTransaction:UsdSend"},
+	"spotSend":              {SpotTransferSignTypes, "HyperliquidTransaction:SpotSend"},
+	"withdraw3":             {WithdrawSignTypes, "HyperliquidTransaction:Withdraw"},
+	"usdClassTransfer":      {USDClassTransferSignTypes, "HyperliquidTransaction:UsdClassTransfer"},
+	"convertToMultiSigUser": {ConvertToMultiSigUserSignTypes, "HyperliquidTransaction:ConvertToMultiSigUser"},
+	"approveAgent":          {AgentSignTypes, "HyperliquidTransaction:ApproveAgent"},
+	"approveBuilderFee":     {BuilderFeeSignTypes, "HyperliquidTransaction:ApproveBuilderFee"},
+	"sendMultiSig":          {MultiSigEnvelopeSignTypes, "HyperliquidTransaction:SendMultiSig"},
+	"cDeposit":              {CDepositSignTypes, "HyperliquidTransaction:CDeposit"},
+	"cWithdraw":             {CWithdrawSignTypes, "HyperliquidTransaction:CWithdraw"},
+	"tokenDelegate":         {TokenDelegateSignTypes, "HyperliquidTransaction:TokenDelegate"},
+}}
+
+// RegisterUserSignedActionType registers (or replaces) the EIP-712 encoding of a
+// user-signed action so it can be signed with SignRegisteredAction
+func RegisterUserSignedActionType(name string, types []SignatureType, primaryType string) error {
+	if name == "" {
+		return errors.New("action type name must be specified")
+	}
+	if primaryType == "" {
+		return errors.New("primary type must be specified")
+	}
+	if !hasSignatureField(types, "hyperliquidChain") {
+		return fmt.Errorf("registering %s: %w", name, ErrInvalidSignatureChain)
+	}
+
+	typesCopy := make([]SignatureType, len(types))
+	copy(typesCopy, types)
+
+	userSignedActionTypes.Lock()
+	userSignedActionTypes.types[name] = UserSignedActionType{PayloadTypes: typesCopy, PrimaryType: primaryType}
+	userSignedActionTypes.Unlock()
+
+	return nil
+}
+
+// LookupUserSignedActionType returns the encoding registered for name. The
+// returned PayloadTypes is a copy, so callers may modify it without affecting
+// concurrent signers
+func LookupUserSignedActionType(name string) (UserSignedActionType, bool) {
+	userSignedActionTypes.RLock()
+	actionType, ok := userSignedActionTypes.types[name]
+	userSignedActionTypes.RUnlock()
+
+	if !ok {
+		return UserSignedActionType{}, false
+	}
+
+	typesCopy := make([]SignatureType, len(actionType.PayloadTypes))
+	copy(typesCopy, actionType.PayloadTypes)
+	actionType.PayloadTypes = typesCopy
+
+	return actionType, true
+}
+
+// SignRegisteredAction signs a user-signed action using the encoding registered for name
+func SignRegisteredAction(wallet Wallet, name string, action map[string]interface{}, isMainnet bool) (Signature, error) {
+	actionType, ok := LookupUserSignedActionType(name)
+	if !ok {
+		return Signature{}, fmt.Errorf("%w: %s", ErrUnknownActionType, name)
+	}
+
+	return SignUserSignedAction(wallet, action, actionType.PayloadTypes, actionType.PrimaryType, isMainnet)
+}
+
+func hasSignatureField(types []SignatureType, name string) bool {
+	for _, t := range types {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
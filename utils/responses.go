@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OrderResponse is the decoded body returned by the /exchange endpoint for order actions
+type OrderResponse struct {
+	Status   string
+	Type     string
+	Statuses []OrderStatus
+	Payload  json.RawMessage // request body submitted, or that would have been in dry-run mode
+}
+
+// OrderStatus is the outcome reported for a single order of a batch
+type OrderStatus struct {
+	Resting *RestingStatus
+	Filled  *FilledStatus
+	Error   string
+	Message string // plain string statuses such as "success" or "waitingForFill"
+}
+
+// RestingStatus describes an order that was placed on the book
+type RestingStatus struct {
+	OID   int64  `json:"oid"`
+	Cloid *Cloid `json:"cloid,omitempty"`
+}
+
+// FilledStatus describes an order that was filled immediately
+type FilledStatus struct {
+	TotalSz float64
+	AvgPx   float64
+	OID     int64
+	Cloid   *Cloid
+}
+
+// OrderError reports an order of a batch that the exchange rejected. Kind is the
+// rejection category (e.g. ErrInsufficientMargin), or nil when it is not recognized
+type OrderError struct {
+	Index   int
+	Message string
+	Kind    error
+}
+
+func (e *OrderError) Error() string {
+	return fmt.Sprintf("order[%d]: %s", e.Index, e.Message)
+}
+
+func (e *OrderError) Unwrap() error {
+	return e.Kind
+}
+
+// rejectionKinds maps fragments of the exchange's rejection messages to their category
+var rejectionKinds = []struct {
+	fragment string
+	kind     error
+}{
+	{"insufficient margin", ErrInsufficientMargin},
+	{"tick size", ErrTickSize},
+	{"minimum value", ErrMinNotional},
+	{"reduce only order would increase position", ErrReduceOnly},
+	{"too many", ErrRateLimited},
+	{"rate limit", ErrRateLimited},
+}
+
+// classifyRejection returns the category of a rejection message, or nil
+func classifyRejection(message string) error {
+	lower := strings.ToLower(message)
+	for _, r := range rejectionKinds {
+		if strings.Contains(lower, r.fragment) {
+			return r.kind
+		}
+	}
+	return nil
+}
+
+// ClassifyResponseError returns nil when an /exchange response reports success for
+// the request and every order in it. Otherwise it returns the top-level rejection
+// (a *NonceError, or an error matching ErrExchangeRejected and its category), or the
+// joined *OrderError of every rejected order; all keep the exchange's message
+func ClassifyResponseError(data []byte) error {
+	resp, err := ParseOrderResponse(data)
+	if err != nil {
+		return err
+	}
+	return errors.Join(resp.Errors()...)
+}
+
+// NonceError is returned when the exchange rejects a request because of its nonce.
+// It matches ErrExchangeRejected with errors.Is
+type NonceError struct {
+	Message string
+}
+
+func (e *NonceError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrExchangeRejected, e.Message)
+}
+
+func (e *NonceError) Unwrap() error {
+	return ErrExchangeRejected
+}
+
+// Recoverable reports whether re-signing with a fresh nonce can succeed: true for
+// nonces that are too low or already used, false for nonces outside the accepted
+// time window, which points at a skewed clock rather than a stale nonce
+func (e *NonceError) Recoverable() bool {
+	message := strings.ToLower(e.Message)
+	return !strings.Contains(message, "too far") && !strings.Contains(message, "too high")
+}
+
+// ParseNonceError returns a *NonceError when message is a nonce rejection
+func ParseNonceError(message string) (*NonceError, bool) {
+	lower := strings.ToLower(message)
+	if !strings.Contains(lower, "nonce") {
+		return nil, false
+	}
+
+	for _, marker := range []string{"too low", "too far", "too high", "already used", "duplicate", "invalid nonce"} {
+		if strings.Contains(lower, marker) {
+			return &NonceError{Message: message}, true
+		}
+	}
+	return nil, false
+}
+
+type rawExchangeResponse struct {
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+type rawOrderResponseBody struct {
+	Type string `json:"type"`
+	Data *struct {
+		Statuses []OrderStatus `json:"statuses"`
+	} `json:"data"`
+}
+
+type rawFilledStatus struct {
+	TotalSz string `json:"totalSz"`
+	AvgPx   string `json:"avgPx"`
+	OID     int64  `json:"oid"`
+	Cloid   *Cloid `json:"cloid,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object and the plain string forms of a status
+func (s *OrderStatus) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*s = OrderStatus{Message: message}
+		return nil
+	}
+
+	var raw struct {
+		Resting *RestingStatus   `json:"resting"`
+		Filled  *rawFilledStatus `json:"filled"`
+		Error   *string          `json:"error"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("decoding order status: %w", err)
+	}
+
+	status := OrderStatus{Resting: raw.Resting}
+	if raw.Error != nil {
+		status.Error = *raw.Error
+	}
+	if raw.Filled != nil {
+		totalSz, err := SafeFloat64(raw.Filled.TotalSz)
+		if err != nil {
+			return fmt.Errorf("parsing filled totalSz: %w", err)
+		}
+		avgPx, err := SafeFloat64(raw.Filled.AvgPx)
+		if err != nil {
+			return fmt.Errorf("parsing filled avgPx: %w", err)
+		}
+		status.Filled = &FilledStatus{
+			TotalSz: totalSz,
+			AvgPx:   avgPx,
+			OID:     raw.Filled.OID,
+			Cloid:   raw.Filled.Cloid,
+		}
+	}
+
+	*s = status
+	return nil
+}
+
+// ParseOrderResponse decodes an /exchange response. A top-level "err" status is
+// returned as an error wrapping ErrExchangeRejected; per-order failures are kept
+// in Statuses and can be inspected with Errors
+func ParseOrderResponse(data []byte) (OrderResponse, error) {
+	var raw rawExchangeResponse
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return OrderResponse{}, fmt.Errorf("decoding exchange response: %w", err)
+	}
+
+	if raw.Status != "ok" {
+		var message string
+		if err := json.Unmarshal(raw.Response, &message); err != nil {
+			message = string(raw.Response)
+		}
+		if nonceErr, ok := ParseNonceError(message); ok {
+			return OrderResponse{Status: raw.Status}, nonceErr
+		}
+		if kind := classifyRejection(message); kind != nil {
+			return OrderResponse{Status: raw.Status}, fmt.Errorf("%w: %w: %s", ErrExchangeRejected, kind, message)
+		}
+		return OrderResponse{Status: raw.Status}, fmt.Errorf("%w: %s", ErrExchangeRejected, message)
+	}
+
+	var body rawOrderResponseBody
+	if err := json.Unmarshal(raw.Response, &body); err != nil {
+		return OrderResponse{}, fmt.Errorf("decoding response body: %w", err)
+	}
+
+	resp := OrderResponse{
+		Status: raw.Status,
+		Type:   body.Type,
+	}
+	if body.Data != nil {
+		resp.Statuses = body.Data.Statuses
+	}
+
+	return resp, nil
+}
+
+// RestingOIDs returns the order IDs of all orders that are resting on the book
+func (r OrderResponse) RestingOIDs() []int64 {
+	oids := make([]int64, 0, len(r.Statuses))
+	for _, status := range r.Statuses {
+		if status.Resting != nil {
+			oids = append(oids, status.Resting.OID)
+		}
+	}
+	return oids
+}
+
+// FilledOIDs returns the order IDs of all orders that were filled immediately
+func (r OrderResponse) FilledOIDs() []int64 {
+	oids := make([]int64, 0, len(r.Statuses))
+	for _, status := range r.Statuses {
+		if status.Filled != nil {
+			oids = append(oids, status.Filled.OID)
+		}
+	}
+	return oids
+}
+
+// Errors returns an *OrderError for every order the exchange rejected
+func (r OrderResponse) Errors() []error {
+	var errs []error
+	for i, status := range r.Statuses {
+		if status.Error != "" {
+			errs = append(errs, &OrderError{Index: i, Message: status.Error, Kind: classifyRejection(status.Error)})
+		}
+	}
+	return errs
+}
+
+// Cloid returns the client order ID echoed back by the exchange, if any
+func (s OrderStatus) Cloid() *Cloid {
+	switch {
+	case s.Resting != nil && s.Resting.Cloid != nil:
+		return s.Resting.Cloid
+	case s.Filled != nil && s.Filled.Cloid != nil:
+		return s.Filled.Cloid
+	}
+	return nil
+}
+
+// CloidStatuses correlates each status with the order that produced it. The cloid
+// echoed by the exchange is preferred; otherwise the status is aligned by position
+// with the submitted orders. Orders without any cloid are left out.
+// Keys are normalized with Cloid.Normalize, so callers must look statuses up by
+// cloid.Normalize() rather than by the spelling they submitted
+func (r OrderResponse) CloidStatuses(submitted []OrderWire) map[Cloid]OrderStatus {
+	result := make(map[Cloid]OrderStatus, len(r.Statuses))
+	for i, status := range r.Statuses {
+		if cloid := status.Cloid(); cloid != nil {
+			result[cloid.Normalize()] = status
+			continue
+		}
+		if i < len(submitted) && submitted[i].Cloid != nil {
+			result[Cloid(*submitted[i].Cloid).Normalize()] = status
+		}
+	}
+	return result
+}
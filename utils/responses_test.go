@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestCloidStatusesNormalizesKeys(t *testing.T) {
+	const (
+		echoed    = "0x000000000000000000000000000000AB"
+		submitted = "0X000000000000000000000000000000CD"
+	)
+	echoedCloid := Cloid(echoed)
+	wires := []OrderWire{{Asset: 4}, {Asset: 4, Cloid: func() *string { s := submitted; return &s }()}, {Asset: 4}}
+	resp := OrderResponse{Statuses: []OrderStatus{
+		{Resting: &RestingStatus{OID: 1, Cloid: &echoedCloid}},
+		{Error: "Insufficient margin to place order."},
+		{Resting: &RestingStatus{OID: 3}},
+	}}
+
+	got := resp.CloidStatuses(wires)
+	if len(got) != 2 {
+		t.Fatalf("CloidStatuses = %v, want 2 entries", got)
+	}
+
+	status, ok := got[Cloid(echoed).Normalize()]
+	if !ok || status.Resting == nil || status.Resting.OID != 1 {
+		t.Errorf("echoed cloid status = %+v, %v", status, ok)
+	}
+	status, ok = got[Cloid(submitted).Normalize()]
+	if !ok || status.Error == "" {
+		t.Errorf("submitted cloid status = %+v, %v", status, ok)
+	}
+	if _, ok := got[Cloid(echoed)]; ok {
+		t.Error("status keyed by the unnormalized spelling")
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// OrderNotional returns the notional value of order, size times price. Trigger
+// orders are valued at their trigger price, limit orders at their limit price
+func OrderNotional(order OrderRequest) (float64, error) {
+	price := order.LimitPrice
+	if order.OrderType.Trigger != nil {
+		price = order.OrderType.Trigger.TriggerPx
+	}
+
+	if math.IsNaN(order.Size) || math.IsInf(order.Size, 0) || order.Size < 0 {
+		return 0, fmt.Errorf("invalid size: %v", order.Size)
+	}
+	if math.IsNaN(price) || math.IsInf(price, 0) || price <= 0 {
+		return 0, fmt.Errorf("invalid reference price: %v", price)
+	}
+
+	notional := decimal.NewFromFloat(order.Size).Mul(decimal.NewFromFloat(price))
+	return notional.InexactFloat64(), nil
+}
+
+// RequiredMargin returns the initial margin order needs at the given leverage,
+// its notional divided by leverage
+func RequiredMargin(order OrderRequest, leverage int) (float64, error) {
+	if leverage <= 0 {
+		return 0, fmt.Errorf("leverage must be positive, got %d", leverage)
+	}
+
+	notional, err := OrderNotional(order)
+	if err != nil {
+		return 0, err
+	}
+
+	return notional / float64(leverage), nil
+}
+
+// ValidateAgainstPosition checks that a reduce-only order is on the side that
+// reduces positionSize (signed, negative for shorts): a buy needs a short position
+// and a sell a long one. Orders that are not reduce-only always pass
+func ValidateAgainstPosition(order OrderRequest, positionSize float64) error {
+	if !order.ReduceOnly {
+		return nil
+	}
+
+	switch {
+	case positionSize == 0:
+		return fmt.Errorf("reduce-only order on %s: no open position to reduce", order.Coin)
+	case order.IsBuy && positionSize > 0:
+		return fmt.Errorf("reduce-only buy on %s would increase long position %v", order.Coin, positionSize)
+	case !order.IsBuy && positionSize < 0:
+		return fmt.Errorf("reduce-only sell on %s would increase short position %v", order.Coin, positionSize)
+	}
+	return nil
+}
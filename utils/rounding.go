@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// RoundingMode selects how values are rounded to a number of decimal places
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // half away from zero
+	RoundDown                         // toward negative infinity
+	RoundUp                           // toward positive infinity
+	RoundHalfEven                     // half to even (banker's rounding)
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfUp:
+		return "HalfUp"
+	case RoundDown:
+		return "Down"
+	case RoundUp:
+		return "Up"
+	case RoundHalfEven:
+		return "HalfEven"
+	default:
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+}
+
+// round rounds d to places decimal places according to mode
+func (m RoundingMode) round(d decimal.Decimal, places int) (decimal.Decimal, error) {
+	p := int32(places)
+	switch m {
+	case RoundHalfUp:
+		return d.Round(p), nil
+	case RoundDown:
+		return d.RoundFloor(p), nil
+	case RoundUp:
+		return d.RoundCeil(p), nil
+	case RoundHalfEven:
+		return d.RoundBank(p), nil
+	default:
+		return decimal.Decimal{}, fmt.Errorf("unknown rounding mode: %v", m)
+	}
+}
+
+// RoundFloat64Mode rounds x to places decimal places according to mode. The
+// rounding is done on the shortest decimal representation of x, so 0.145 rounds
+// half up to 0.15. Unknown modes and non-finite values return x unchanged
+func RoundFloat64Mode(x float64, places int, mode RoundingMode) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return x
+	}
+
+	d, err := mode.round(decimal.NewFromFloat(x), places)
+	if err != nil {
+		return x
+	}
+
+	return d.InexactFloat64()
+}
+
+// FloatToWireMode rounds x to places decimal places according to mode and returns
+// its wire representation. Unlike FloatToWire it never reports precision loss
+func FloatToWireMode(x float64, places int, mode RoundingMode) (string, error) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return "", fmt.Errorf("invalid float value: %v", x)
+	}
+
+	d, err := mode.round(decimal.NewFromFloat(x), places)
+	if err != nil {
+		return "", err
+	}
+
+	return d.String(), nil
+}
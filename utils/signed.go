@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SignedAction bundles an action with the nonce, vault address and signature it
+// was signed with, i.e. everything needed to submit it
+type SignedAction struct {
+	Action       interface{}
+	Nonce        uint64
+	VaultAddress string
+	Signature    Signature
+}
+
+// ToPayload returns the /exchange request body. vaultAddress is always present and
+// null when the action is not signed on behalf of a vault or sub-account
+func (s SignedAction) ToPayload() map[string]interface{} {
+	payload := map[string]interface{}{
+		"action":    s.Action,
+		"nonce":     s.Nonce,
+		"signature": s.Signature,
+	}
+	if s.VaultAddress != "" {
+		payload["vaultAddress"] = s.VaultAddress
+	} else {
+		payload["vaultAddress"] = nil
+	}
+	return payload
+}
+
+// NewSignedL1Action signs an L1 action with SignL1Action and bundles the result
+func NewSignedL1Action(wallet Wallet, action interface{}, vaultAddress string, nonce uint64, isMainnet bool) (SignedAction, error) {
+	sig, err := SignL1Action(wallet, action, vaultAddress, nonce, isMainnet)
+	if err != nil {
+		return SignedAction{}, err
+	}
+
+	return SignedAction{
+		Action:       action,
+		Nonce:        nonce,
+		VaultAddress: vaultAddress,
+		Signature:    sig,
+	}, nil
+}
+
+// NewSignedOrderAction signs an order action with SignOrderAction and bundles the result
+func NewSignedOrderAction(wallet Wallet, orderAction OrderAction, vaultAddress string, nonce uint64, isMainnet bool) (SignedAction, error) {
+	sig, err := SignOrderAction(wallet, orderAction, vaultAddress, nonce, isMainnet)
+	if err != nil {
+		return SignedAction{}, err
+	}
+
+	return SignedAction{
+		Action:       orderAction,
+		Nonce:        nonce,
+		VaultAddress: vaultAddress,
+		Signature:    sig,
+	}, nil
+}
+
+// NewSignedRegisteredAction prepares a user-signed action (see PrepareUserSignedAction),
+// signs it with the encoding registered for name and bundles the result. nonce must
+// equal the action's own time or nonce field
+func NewSignedRegisteredAction(wallet Wallet, name string, action map[string]interface{}, nonce uint64, isMainnet bool) (SignedAction, error) {
+	prepared := PrepareUserSignedAction(action, isMainnet)
+	prepared["type"] = name
+
+	sig, err := SignRegisteredAction(wallet, name, prepared, isMainnet)
+	if err != nil {
+		return SignedAction{}, fmt.Errorf("signing %s: %w", name, err)
+	}
+
+	return SignedAction{
+		Action:    prepared,
+		Nonce:     nonce,
+		Signature: sig,
+	}, nil
+}
+
+// BuildLeverageThenOrder signs an updateLeverage action for coin followed by an
+// order action placing order, with strictly increasing nonces from nonces; pass the
+// NonceManager used for the wallet's other actions so no nonce is reused. The two
+// actions are returned in submission order and must be submitted sequentially, the
+// order only after the leverage update succeeded
+func BuildLeverageThenOrder(
+	wallet Wallet,
+	coin string,
+	isCross bool,
+	leverage int,
+	order OrderRequest,
+	assetMap map[string]int,
+	nonces *NonceManager,
+	isMainnet bool,
+) ([]SignedAction, error) {
+	if nonces == nil {
+		return nil, errors.New("nil NonceManager")
+	}
+	if order.Coin != coin {
+		return nil, fmt.Errorf("order is for %s, leverage update for %s", order.Coin, coin)
+	}
+
+	asset, ok := LookupAsset(assetMap, coin)
+	if !ok {
+		return nil, fmt.Errorf("unknown asset: %s", coin)
+	}
+
+	leverageAction, err := CreateUpdateLeverageAction(asset, isCross, leverage)
+	if err != nil {
+		return nil, err
+	}
+
+	wires, err := BatchOrdersToWire([]OrderRequest{order}, assetMap)
+	if err != nil {
+		return nil, err
+	}
+	orderAction := OrderWiresToOrderAction(wires, "")
+
+	signedLeverage, err := NewSignedL1Action(wallet, leverageAction, "", nonces.Next(), isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("signing leverage update: %w", err)
+	}
+
+	signedOrder, err := NewSignedOrderAction(wallet, orderAction, "", nonces.Next(), isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("signing order: %w", err)
+	}
+
+	return []SignedAction{signedLeverage, signedOrder}, nil
+}
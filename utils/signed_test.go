@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestBuildLeverageThenOrderUsesCallerNonces(t *testing.T) {
+	wallet := testWallet(t)
+	assetMap := map[string]int{"ETH": 4}
+	order := CreateLimitOrder("ETH", true, 0.0147, 1670.1, TIFIoc, false, nil)
+
+	nonces := NewNonceManager()
+	before := nonces.Next()
+
+	signed, err := BuildLeverageThenOrder(wallet, "ETH", true, 10, order, assetMap, nonces, true)
+	if err != nil {
+		t.Fatalf("BuildLeverageThenOrder: %v", err)
+	}
+	if len(signed) != 2 {
+		t.Fatalf("got %d actions, want 2", len(signed))
+	}
+	if _, ok := signed[0].Action.(UpdateLeverageAction); !ok {
+		t.Errorf("first action is %T, want UpdateLeverageAction", signed[0].Action)
+	}
+	if !(before < signed[0].Nonce && signed[0].Nonce < signed[1].Nonce) {
+		t.Errorf("nonces %d, %d not increasing after %d", signed[0].Nonce, signed[1].Nonce, before)
+	}
+	if after := nonces.Next(); after <= signed[1].Nonce {
+		t.Errorf("NonceManager not advanced: Next = %d after %d", after, signed[1].Nonce)
+	}
+}
+
+func TestBuildLeverageThenOrderRejectsNilNonceManager(t *testing.T) {
+	order := CreateLimitOrder("ETH", true, 0.0147, 1670.1, TIFIoc, false, nil)
+
+	if _, err := BuildLeverageThenOrder(testWallet(t), "ETH", true, 10, order, map[string]int{"ETH": 4}, nil, true); err == nil {
+		t.Fatal("no error for a nil NonceManager")
+	}
+}
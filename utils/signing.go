@@ -2,8 +2,11 @@ package utils
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 
-	"github.com/vmihailenco/msgpack/v5"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func (d EIP712Domain) ToMap() map[string]interface{} {
@@ -25,15 +28,38 @@ func DefaultExchangeDomain() EIP712Domain {
 }
 
 func DefaultHyperliquidDomain() EIP712Domain {
+	return HyperliquidDomain(ArbitrumSepoliaChainID)
+}
+
+// UserSignedActionDomain returns the user-signed action domain for the target
+// network: Arbitrum One (0xa4b1) for mainnet, Arbitrum Sepolia (0x66eee) for testnet
+func UserSignedActionDomain(isMainnet bool) EIP712Domain {
+	if isMainnet {
+		return HyperliquidDomain(ArbitrumOneChainID)
+	}
+	return HyperliquidDomain(ArbitrumSepoliaChainID)
+}
+
+// HyperliquidDomain returns the user-signed action domain for the given chain ID
+func HyperliquidDomain(chainID int64) EIP712Domain {
 	return EIP712Domain{
 		Name:              "HyperliquidSignTransaction",
 		Version:           "1",
-		ChainID:           421614,
+		ChainID:           chainID,
 		VerifyingContract: "0x0000000000000000000000000000000000000000",
 	}
 }
 
+// SignatureChainID returns the domain chain ID in the 0x-hex form used by the
+// signatureChainId action field
+func (d EIP712Domain) SignatureChainID() string {
+	return "0x" + strconv.FormatInt(d.ChainID, 16)
+}
+
 // SignatureTypesToMap converts SignatureType slices to the map format expected by EIP-712
+//
+// Deprecated: maps do not keep name before type when encoded. Pass the
+// SignatureType slice to SignUserSignedAction, which builds the typed data from it
 func SignatureTypesToMap(types []SignatureType) []map[string]string {
 	result := make([]map[string]string, len(types))
 	for i, t := range types {
@@ -45,6 +71,45 @@ func SignatureTypesToMap(types []SignatureType) []map[string]string {
 	return result
 }
 
+// eip712Field is one member of an EIP-712 struct type. Being a struct, it always
+// encodes as name then type, independently of any map key ordering
+type eip712Field struct {
+	Name string `json:"name" msgpack:"name"`
+	Type string `json:"type" msgpack:"type"`
+}
+
+func eip712Fields(types []SignatureType) []eip712Field {
+	fields := make([]eip712Field, len(types))
+	for i, t := range types {
+		fields[i] = eip712Field{Name: t.Name, Type: t.Type}
+	}
+	return fields
+}
+
+// encodeType returns the EIP-712 encodeType string of a struct type, its name
+// followed by its members in declaration order, e.g.
+// "Agent(string source,bytes32 connectionId)"
+func encodeType(primaryType string, fields []SignatureType) string {
+	var b strings.Builder
+	b.WriteString(primaryType)
+	b.WriteByte('(')
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(f.Type)
+		b.WriteByte(' ')
+		b.WriteString(f.Name)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// typeHash returns the EIP-712 typeHash, keccak256(encodeType(primaryType, fields))
+func typeHash(primaryType string, fields []SignatureType) []byte {
+	return crypto.Keccak256([]byte(encodeType(primaryType, fields)))
+}
+
 // createEIP712TypedData creates a properly formatted EIP-712 typed data structure
 func createEIP712TypedData(
 	domain EIP712Domain,
@@ -54,9 +119,9 @@ func createEIP712TypedData(
 ) map[string]interface{} {
 	typesMap := make(map[string]interface{}, len(types)+1)
 	for typeName, typeFields := range types {
-		typesMap[typeName] = SignatureTypesToMap(typeFields)
+		typesMap[typeName] = eip712Fields(typeFields)
 	}
-	typesMap["EIP712Domain"] = SignatureTypesToMap(EIP712DomainFields)
+	typesMap["EIP712Domain"] = eip712Fields(EIP712DomainFields)
 
 	return map[string]interface{}{
 		"domain":      domain.ToMap(),
@@ -68,9 +133,53 @@ func createEIP712TypedData(
 
 // SignL1Action signs an L1 action
 func SignL1Action(wallet Wallet, action interface{}, vaultAddress string, nonce uint64, isMainnet bool) (Signature, error) {
-	hash, err := ActionHash(action, vaultAddress, nonce)
+	return SignL1ActionWithExpiry(wallet, action, vaultAddress, nonce, nil, isMainnet)
+}
+
+// SignL1ActionWithExpiry signs an L1 action that the exchange must reject once
+// expiresAfter (ms timestamp) has passed; a nil expiresAfter behaves like SignL1Action
+func SignL1ActionWithExpiry(wallet Wallet, action interface{}, vaultAddress string, nonce uint64, expiresAfter *uint64, isMainnet bool) (Signature, error) {
+	encodedData, err := encodeL1TypedData(action, vaultAddress, nonce, expiresAfter, isMainnet)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	sig, err := wallet.SignMessage(encodedData)
 	if err != nil {
-		return Signature{}, fmt.Errorf("computing action hash: %w", err)
+		return Signature{}, err
+	}
+
+	if signingLogger.Load().enabled {
+		hash, _ := ActionHashWithExpiry(action, vaultAddress, nonce, expiresAfter)
+		logSignature("Agent", hash, encodedData, sig)
+	}
+
+	return sig, nil
+}
+
+// L1ActionSigningHash returns the 32-byte hash SignL1Action has the wallet sign,
+// HashMessage of the msgpack-encoded phantom agent typed data, so it can be handed
+// to an external signer. It is not the EIP-712 digest (0x1901 ‖ domainSeparator ‖
+// structHash) and only matches what this package signs
+func L1ActionSigningHash(action interface{}, vaultAddress string, nonce uint64, isMainnet bool) ([]byte, error) {
+	return L1ActionSigningHashWithExpiry(action, vaultAddress, nonce, nil, isMainnet)
+}
+
+// L1ActionSigningHashWithExpiry is L1ActionSigningHash for SignL1ActionWithExpiry
+func L1ActionSigningHashWithExpiry(action interface{}, vaultAddress string, nonce uint64, expiresAfter *uint64, isMainnet bool) ([]byte, error) {
+	encodedData, err := encodeL1TypedData(action, vaultAddress, nonce, expiresAfter, isMainnet)
+	if err != nil {
+		return nil, err
+	}
+
+	return HashMessage(encodedData), nil
+}
+
+// encodeL1TypedData builds and encodes the phantom agent typed data signed for an L1 action
+func encodeL1TypedData(action interface{}, vaultAddress string, nonce uint64, expiresAfter *uint64, isMainnet bool) ([]byte, error) {
+	hash, err := ActionHashWithExpiry(action, vaultAddress, nonce, expiresAfter)
+	if err != nil {
+		return nil, fmt.Errorf("computing action hash: %w", err)
 	}
 
 	agentMessage := ConstructPhantomAgent(hash, isMainnet)
@@ -91,64 +200,156 @@ func SignL1Action(wallet Wallet, action interface{}, vaultAddress string, nonce
 		types,
 	)
 
-	encodedData, err := msgpack.Marshal(typedData)
+	// Sorted map keys keep the encoding, and hence the signature, deterministic
+	encodedData, err := MarshalAction(typedData)
 	if err != nil {
-		return Signature{}, fmt.Errorf("encoding typed data: %w", err)
+		return nil, fmt.Errorf("encoding typed data: %w", err)
 	}
 
-	return wallet.SignMessage(encodedData)
+	return encodedData, nil
 }
 
-func SignUserSignedAction(
-	wallet Wallet,
-	action map[string]interface{},
-	payloadTypes []SignatureType,
-	primaryType string,
-	isMainnet bool,
-) (Signature, error) {
+// PrepareUserSignedAction returns a copy of action carrying the signatureChainId and
+// hyperliquidChain fields, i.e. the action exactly as it must be submitted
+func PrepareUserSignedAction(action map[string]interface{}, isMainnet bool) map[string]interface{} {
+	return PrepareUserSignedActionWithDomain(action, UserSignedActionDomain(isMainnet), isMainnet)
+}
+
+// PrepareUserSignedActionWithDomain is PrepareUserSignedAction with the
+// signatureChainId taken from domain
+func PrepareUserSignedActionWithDomain(action map[string]interface{}, domain EIP712Domain, isMainnet bool) map[string]interface{} {
 	actionCopy := make(map[string]interface{}, len(action)+2)
 	for k, v := range action {
 		actionCopy[k] = v
 	}
 
-	actionCopy["signatureChainId"] = "0x66eee"
+	actionCopy["signatureChainId"] = domain.SignatureChainID()
 	if isMainnet {
 		actionCopy["hyperliquidChain"] = "Mainnet"
 	} else {
 		actionCopy["hyperliquidChain"] = "Testnet"
 	}
 
+	return actionCopy
+}
+
+// SignTypedL1Action signs an L1 action given as a struct (or pointer to struct) with
+// msgpack tags. The struct is hashed directly, so its fields are encoded in
+// declaration order, which must match the order the exchange expects
+func SignTypedL1Action(wallet Wallet, action interface{}, vaultAddress string, nonce uint64, isMainnet bool) (Signature, error) {
+	v := reflect.ValueOf(action)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return Signature{}, fmt.Errorf("action must not be nil")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return Signature{}, fmt.Errorf("action must be a struct, got %T", action)
+	}
+
+	return SignL1Action(wallet, action, vaultAddress, nonce, isMainnet)
+}
+
+func SignUserSignedAction(
+	wallet Wallet,
+	action map[string]interface{},
+	payloadTypes []SignatureType,
+	primaryType string,
+	isMainnet bool,
+) (Signature, error) {
+	return SignUserSignedActionWithDomain(wallet, action, payloadTypes, primaryType, UserSignedActionDomain(isMainnet), isMainnet)
+}
+
+// SignUserSignedActionWithDomain signs a user-signed action under domain; the
+// action's signatureChainId is set to match the domain's chain ID
+func SignUserSignedActionWithDomain(
+	wallet Wallet,
+	action map[string]interface{},
+	payloadTypes []SignatureType,
+	primaryType string,
+	domain EIP712Domain,
+	isMainnet bool,
+) (Signature, error) {
+	encodedData, err := encodeUserSignedTypedData(action, payloadTypes, primaryType, domain, isMainnet)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	sig, err := wallet.SignMessage(encodedData)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	if signingLogger.Load().enabled {
+		logSignature(primaryType, nil, encodedData, sig)
+	}
+
+	return sig, nil
+}
+
+// UserSignedActionSigningHash returns the 32-byte hash SignUserSignedAction has the
+// wallet sign, HashMessage of the msgpack-encoded typed data. Like
+// L1ActionSigningHash it is not the EIP-712 digest
+func UserSignedActionSigningHash(action map[string]interface{}, payloadTypes []SignatureType, primaryType string, isMainnet bool) ([]byte, error) {
+	return UserSignedActionSigningHashWithDomain(action, payloadTypes, primaryType, UserSignedActionDomain(isMainnet), isMainnet)
+}
+
+// UserSignedActionSigningHashWithDomain is UserSignedActionSigningHash for
+// SignUserSignedActionWithDomain
+func UserSignedActionSigningHashWithDomain(
+	action map[string]interface{},
+	payloadTypes []SignatureType,
+	primaryType string,
+	domain EIP712Domain,
+	isMainnet bool,
+) ([]byte, error) {
+	encodedData, err := encodeUserSignedTypedData(action, payloadTypes, primaryType, domain, isMainnet)
+	if err != nil {
+		return nil, err
+	}
+
+	return HashMessage(encodedData), nil
+}
+
+// encodeUserSignedTypedData builds and encodes the typed data signed for a user-signed action
+func encodeUserSignedTypedData(
+	action map[string]interface{},
+	payloadTypes []SignatureType,
+	primaryType string,
+	domain EIP712Domain,
+	isMainnet bool,
+) ([]byte, error) {
+	actionCopy := PrepareUserSignedActionWithDomain(action, domain, isMainnet)
+
 	types := map[string][]SignatureType{
 		primaryType: payloadTypes,
 	}
 
 	typedData := createEIP712TypedData(
-		DefaultHyperliquidDomain(),
+		domain,
 		primaryType,
 		actionCopy,
 		types,
 	)
 
-	encodedData, err := msgpack.Marshal(typedData)
+	// Sorted map keys keep the encoding, and hence the signature, deterministic
+	encodedData, err := MarshalAction(typedData)
 	if err != nil {
-		return Signature{}, fmt.Errorf("encoding typed data: %w", err)
+		return nil, fmt.Errorf("encoding typed data: %w", err)
 	}
 
-	return wallet.SignMessage(encodedData)
+	return encodedData, nil
 }
 
+// SignOrderAction signs an order action. The struct is hashed directly so its
+// fields keep the type, orders, grouping, builder order the exchange hashes
 func SignOrderAction(wallet Wallet, orderAction OrderAction, vaultAddress string, nonce uint64, isMainnet bool) (Signature, error) {
-	actionMap, err := msgpack.Marshal(orderAction)
-	if err != nil {
-		return Signature{}, fmt.Errorf("marshalling order action: %w", err)
+	if !orderAction.Grouping.Valid() {
+		return Signature{}, fmt.Errorf("invalid grouping %q", orderAction.Grouping)
 	}
 
-	var action map[string]interface{}
-	if err := msgpack.Unmarshal(actionMap, &action); err != nil {
-		return Signature{}, fmt.Errorf("unmarshalling to map: %w", err)
-	}
-
-	return SignL1Action(wallet, action, vaultAddress, nonce, isMainnet)
+	return SignTypedL1Action(wallet, orderAction, vaultAddress, nonce, isMainnet)
 }
 
 func SignBatchOrderAction(wallet Wallet, orders []OrderWire, grouping GroupingType, builder string, vaultAddress string, nonce uint64, isMainnet bool) (Signature, error) {
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testPrivateKey = "0x0123456789012345678901234567890123456789012345678901234567890123"
+
+func testWallet(t testing.TB) *PrivateKeyWallet {
+	t.Helper()
+	wallet, err := NewPrivateKeyWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewPrivateKeyWallet: %v", err)
+	}
+	return wallet
+}
+
+// domainSeparator is the EIP-712 hashStruct of d
+func domainSeparator(d EIP712Domain) []byte {
+	var buf bytes.Buffer
+	buf.Write(typeHash("EIP712Domain", EIP712DomainFields))
+	buf.Write(crypto.Keccak256([]byte(d.Name)))
+	buf.Write(crypto.Keccak256([]byte(d.Version)))
+	buf.Write(common.LeftPadBytes(big.NewInt(d.ChainID).Bytes(), 32))
+	buf.Write(common.LeftPadBytes(common.HexToAddress(d.VerifyingContract).Bytes(), 32))
+	return crypto.Keccak256(buf.Bytes())
+}
+
+func TestUserSignedActionDomainByNetwork(t *testing.T) {
+	mainnet := UserSignedActionDomain(true)
+	testnet := UserSignedActionDomain(false)
+
+	if mainnet.ChainID != ArbitrumOneChainID || mainnet.SignatureChainID() != "0xa4b1" {
+		t.Errorf("mainnet chain = %d (%s), want %d (0xa4b1)", mainnet.ChainID, mainnet.SignatureChainID(), ArbitrumOneChainID)
+	}
+	if testnet.ChainID != ArbitrumSepoliaChainID || testnet.SignatureChainID() != "0x66eee" {
+		t.Errorf("testnet chain = %d (%s), want %d (0x66eee)", testnet.ChainID, testnet.SignatureChainID(), ArbitrumSepoliaChainID)
+	}
+
+	if bytes.Equal(domainSeparator(mainnet), domainSeparator(testnet)) {
+		t.Fatal("mainnet and testnet domain separators are equal")
+	}
+}
+
+func TestUserSignedActionSignatureByNetwork(t *testing.T) {
+	wallet := testWallet(t)
+	action := CreateUSDTransferAction("0x1719884eb866cb12b2287399b15f7db5e7d775ea", "1", fixtureNonce)
+
+	mainnetDigest, err := UserSignedActionSigningHash(action, USDSendSignTypes, "HyperliquidTransaction:UsdSend", true)
+	if err != nil {
+		t.Fatalf("mainnet digest: %v", err)
+	}
+	testnetDigest, err := UserSignedActionSigningHash(action, USDSendSignTypes, "HyperliquidTransaction:UsdSend", false)
+	if err != nil {
+		t.Fatalf("testnet digest: %v", err)
+	}
+	if bytes.Equal(mainnetDigest, testnetDigest) {
+		t.Fatal("mainnet and testnet digests are equal")
+	}
+
+	mainnetSig, err := SignUSDTransferAction(wallet, action, true)
+	if err != nil {
+		t.Fatalf("mainnet sign: %v", err)
+	}
+	testnetSig, err := SignUSDTransferAction(wallet, action, false)
+	if err != nil {
+		t.Fatalf("testnet sign: %v", err)
+	}
+	if mainnetSig.R == testnetSig.R && mainnetSig.S == testnetSig.S {
+		t.Fatal("mainnet and testnet signatures are equal")
+	}
+}
+
+func TestEncodeType(t *testing.T) {
+	tests := []struct {
+		primaryType string
+		fields      []SignatureType
+		want        string
+	}{
+		{
+			primaryType: "HyperliquidTransaction:ApproveAgent",
+			fields:      AgentSignTypes,
+			want:        "HyperliquidTransaction:ApproveAgent(string hyperliquidChain,address agentAddress,string agentName,uint64 nonce)",
+		},
+		{
+			primaryType: "HyperliquidTransaction:Withdraw",
+			fields:      WithdrawSignTypes,
+			want:        "HyperliquidTransaction:Withdraw(string hyperliquidChain,string destination,string amount,uint64 time)",
+		},
+		{
+			primaryType: "EIP712Domain",
+			fields:      EIP712DomainFields,
+			want:        "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := encodeType(tt.primaryType, tt.fields); got != tt.want {
+			t.Errorf("encodeType(%s)\n got %s\nwant %s", tt.primaryType, got, tt.want)
+		}
+	}
+}
+
+func TestTypeHashEIP712Domain(t *testing.T) {
+	// The well-known typeHash of the standard EIP712Domain
+	const want = "0x8b73c3c69bb8fe3d512ecc4cf759cc79239f7b179b0ffacaa9a75d522b39400f"
+
+	if got := hexutil.Encode(typeHash("EIP712Domain", EIP712DomainFields)); got != want {
+		t.Errorf("typeHash = %s, want %s", got, want)
+	}
+}
+
+// recoverFromHash returns the address that produced sig over the 32-byte hash
+func recoverFromHash(t *testing.T, hash []byte, sig Signature) string {
+	t.Helper()
+	raw := append(append(hexutil.MustDecode(sig.R), hexutil.MustDecode(sig.S)...), sig.V-27)
+	pub, err := crypto.SigToPub(hash, raw)
+	if err != nil {
+		t.Fatalf("SigToPub: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub).Hex()
+}
+
+func TestSigningHashRecoversSigner(t *testing.T) {
+	wallet := testWallet(t)
+	want := wallet.Address().Hex()
+	action := fixtureCancelAction()
+	expiresAfter := fixtureNonce + 60000
+
+	t.Run("L1", func(t *testing.T) {
+		sig, err := SignL1Action(wallet, action, "", fixtureNonce, true)
+		if err != nil {
+			t.Fatalf("SignL1Action: %v", err)
+		}
+		hash, err := L1ActionSigningHash(action, "", fixtureNonce, true)
+		if err != nil {
+			t.Fatalf("L1ActionSigningHash: %v", err)
+		}
+		if got := recoverFromHash(t, hash, sig); got != want {
+			t.Errorf("recovered %s, want %s", got, want)
+		}
+	})
+
+	t.Run("L1 with expiry", func(t *testing.T) {
+		sig, err := SignL1ActionWithExpiry(wallet, action, "", fixtureNonce, &expiresAfter, true)
+		if err != nil {
+			t.Fatalf("SignL1ActionWithExpiry: %v", err)
+		}
+		hash, err := L1ActionSigningHashWithExpiry(action, "", fixtureNonce, &expiresAfter, true)
+		if err != nil {
+			t.Fatalf("L1ActionSigningHashWithExpiry: %v", err)
+		}
+		if got := recoverFromHash(t, hash, sig); got != want {
+			t.Errorf("recovered %s, want %s", got, want)
+		}
+
+		plain, err := L1ActionSigningHash(action, "", fixtureNonce, true)
+		if err != nil {
+			t.Fatalf("L1ActionSigningHash: %v", err)
+		}
+		if bytes.Equal(plain, hash) {
+			t.Error("expiresAfter did not change the signing hash")
+		}
+	})
+
+	t.Run("user-signed", func(t *testing.T) {
+		transfer := CreateUSDTransferAction("0x1719884eb866cb12b2287399b15f7db5e7d775ea", "1", fixtureNonce)
+		sig, err := SignUSDTransferAction(wallet, transfer, false)
+		if err != nil {
+			t.Fatalf("SignUSDTransferAction: %v", err)
+		}
+		hash, err := UserSignedActionSigningHash(transfer, USDSendSignTypes, "HyperliquidTransaction:UsdSend", false)
+		if err != nil {
+			t.Fatalf("UserSignedActionSigningHash: %v", err)
+		}
+		if got := recoverFromHash(t, hash, sig); got != want {
+			t.Errorf("recovered %s, want %s", got, want)
+		}
+	})
+
+	t.Run("user-signed with domain", func(t *testing.T) {
+		transfer := CreateUSDTransferAction("0x1719884eb866cb12b2287399b15f7db5e7d775ea", "1", fixtureNonce)
+		domain := HyperliquidDomain(1)
+		sig, err := SignUserSignedActionWithDomain(wallet, transfer, USDSendSignTypes, "HyperliquidTransaction:UsdSend", domain, true)
+		if err != nil {
+			t.Fatalf("SignUserSignedActionWithDomain: %v", err)
+		}
+		hash, err := UserSignedActionSigningHashWithDomain(transfer, USDSendSignTypes, "HyperliquidTransaction:UsdSend", domain, true)
+		if err != nil {
+			t.Fatalf("UserSignedActionSigningHashWithDomain: %v", err)
+		}
+		if got := recoverFromHash(t, hash, sig); got != want {
+			t.Errorf("recovered %s, want %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// SimpleL1Action is an L1 action made of a type tag and flat fields, for actions
+// without a dedicated type. It encodes type first, then the fields sorted by key,
+// so it hashes like the reference SDK's dict when the fields were inserted sorted
+type SimpleL1Action struct {
+	Type   string
+	Fields map[string]interface{}
+}
+
+// CreateSimpleL1Action builds an L1 action of type actionType carrying fields; sign
+// it with SignL1Action. fields is copied and must not contain a "type" key
+func CreateSimpleL1Action(actionType string, fields map[string]interface{}) (SimpleL1Action, error) {
+	if actionType == "" {
+		return SimpleL1Action{}, errors.New("action type must be specified")
+	}
+	if _, ok := fields["type"]; ok {
+		return SimpleL1Action{}, fmt.Errorf("%s action: fields must not contain the type tag", actionType)
+	}
+
+	fieldsCopy := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		fieldsCopy[k] = v
+	}
+
+	return SimpleL1Action{Type: actionType, Fields: fieldsCopy}, nil
+}
+
+// CreateSetDisplayNameAction builds the setDisplayName action; an empty name
+// clears the display name
+func CreateSetDisplayNameAction(name string) SimpleL1Action {
+	return SimpleL1Action{Type: "setDisplayName", Fields: map[string]interface{}{"displayName": name}}
+}
+
+func (a SimpleL1Action) sortedKeys() []string {
+	keys := make([]string, 0, len(a.Fields))
+	for k := range a.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EncodeMsgpack implements msgpack.CustomEncoder
+func (a SimpleL1Action) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeMapLen(len(a.Fields) + 1); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("type"); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(a.Type); err != nil {
+		return err
+	}
+	for _, k := range a.sortedKeys() {
+		if err := enc.EncodeString(k); err != nil {
+			return err
+		}
+		if err := enc.Encode(a.Fields[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON emits the fields merged with the type tag
+func (a SimpleL1Action) MarshalJSON() ([]byte, error) {
+	merged := make(map[string]interface{}, len(a.Fields)+1)
+	for k, v := range a.Fields {
+		merged[k] = v
+	}
+	merged["type"] = a.Type
+	return json.Marshal(merged)
+}
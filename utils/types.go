@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -11,20 +16,69 @@ var (
 	ErrPrecisionLoss         = errors.New("conversion would cause precision loss")
 	ErrInvalidAddress        = errors.New("invalid ethereum address format")
 	ErrInvalidSignatureChain = errors.New("hyperliquidChain missing from signature types")
+	ErrExchangeRejected      = errors.New("exchange rejected request")
+	ErrInvalidAmount         = errors.New("invalid amount: expected a plain decimal string")
+	ErrDuplicateCloid        = errors.New("duplicate cloid in batch")
+	ErrInvalidChecksum       = errors.New("address fails EIP-55 checksum")
+	ErrIncompatibleTIF       = errors.New("time in force incompatible with order")
+	ErrInvalidTPSL           = errors.New("invalid tpsl")
+
+	// Rejection categories reported by ClassifyResponseError
+	ErrInsufficientMargin = errors.New("insufficient margin")
+	ErrTickSize           = errors.New("price not divisible by tick size")
+	ErrMinNotional        = errors.New("order below minimum notional")
+	ErrReduceOnly         = errors.New("reduce-only order would increase position")
+	ErrRateLimited        = errors.New("rate limited")
+)
+
+const (
+	ArbitrumOneChainID     int64 = 42161
+	ArbitrumSepoliaChainID int64 = 421614
 )
 
 const (
 	PrecisionThreshold   = 1e-12
 	DefaultDecimalPlaces = 8
 	USDDecimalPlaces     = 6
+	HypeDecimalPlaces    = 8
 )
 
+// Cloid is a client order ID, 16 bytes written as 0x-prefixed hex
 type Cloid string
 
+// NewCloid parses a client order ID given as 32 hex digits, with or without the 0x
+// prefix and in any case, and returns it in canonical lowercase 0x form, so equal
+// IDs compare and hash equal as map keys
+func NewCloid(s string) (Cloid, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(digits) != 32 {
+		return "", fmt.Errorf("invalid cloid %q: expected 16 bytes of hex", s)
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", fmt.Errorf("invalid cloid %q: %w", s, err)
+	}
+	return Cloid("0x" + strings.ToLower(digits)), nil
+}
+
 func (c Cloid) ToRaw() string {
 	return string(c)
 }
 
+// Normalize returns c in canonical lowercase 0x form, or c unchanged when it is not
+// a valid cloid
+func (c Cloid) Normalize() Cloid {
+	normalized, err := NewCloid(string(c))
+	if err != nil {
+		return c
+	}
+	return normalized
+}
+
+// Equal reports whether c and other are the same ID, ignoring case and the 0x prefix
+func (c Cloid) Equal(other Cloid) bool {
+	return c.Normalize() == other.Normalize()
+}
+
 // ----- Order Type Definitions -----
 
 // TIF (Time-in-Force) order type constants
@@ -36,6 +90,66 @@ const (
 	TIFGtc TIF = "Gtc" // Good Till Canceled
 )
 
+// ParseTIF parses a time-in-force case-insensitively, e.g. "gtc" or "GTC"
+func ParseTIF(s string) (TIF, error) {
+	for _, tif := range []TIF{TIFAlo, TIFIoc, TIFGtc} {
+		if strings.EqualFold(s, string(tif)) {
+			return tif, nil
+		}
+	}
+	return "", fmt.Errorf("invalid tif %q: must be one of %s, %s, %s", s, TIFAlo, TIFIoc, TIFGtc)
+}
+
+// Valid reports whether t is one of the exact values the exchange accepts
+func (t TIF) Valid() bool {
+	return t == TIFAlo || t == TIFIoc || t == TIFGtc
+}
+
+// Side is the side of an order, an alternative to the raw IsBuy flag that is
+// harder to invert by accident
+type Side string
+
+const (
+	SideBuy  Side = "B" // bid
+	SideSell Side = "A" // ask
+)
+
+// ParseSide accepts the exchange's "B"/"A" (bid/ask) notation as well as "buy" and
+// "sell" in any case
+func ParseSide(s string) (Side, error) {
+	switch strings.ToLower(s) {
+	case "b", "buy", "bid":
+		return SideBuy, nil
+	case "a", "sell", "ask":
+		return SideSell, nil
+	}
+	return "", fmt.Errorf("invalid side: %q", s)
+}
+
+// SideFromIsBuy converts an IsBuy flag to a Side
+func SideFromIsBuy(isBuy bool) Side {
+	if isBuy {
+		return SideBuy
+	}
+	return SideSell
+}
+
+func (s Side) IsBuy() bool {
+	return s == SideBuy
+}
+
+// Opposite returns the side that closes a position opened on s
+func (s Side) Opposite() Side {
+	return SideFromIsBuy(!s.IsBuy())
+}
+
+func (s Side) String() string {
+	if s.IsBuy() {
+		return "buy"
+	}
+	return "sell"
+}
+
 type TPSL string
 
 const (
@@ -43,6 +157,22 @@ const (
 	TPSLStopLoss   TPSL = "sl"
 )
 
+// ParseTPSL parses "tp" or "sl", case-insensitively
+func ParseTPSL(s string) (TPSL, error) {
+	switch TPSL(strings.ToLower(s)) {
+	case TPSLTakeProfit:
+		return TPSLTakeProfit, nil
+	case TPSLStopLoss:
+		return TPSLStopLoss, nil
+	}
+	return "", fmt.Errorf("%w %q: must be %s or %s", ErrInvalidTPSL, s, TPSLTakeProfit, TPSLStopLoss)
+}
+
+// Valid reports whether t is one of the exact values the exchange accepts
+func (t TPSL) Valid() bool {
+	return t == TPSLTakeProfit || t == TPSLStopLoss
+}
+
 type LimitOrderType struct {
 	TIF TIF `json:"tif" msgpack:"tif"`
 }
@@ -53,9 +183,10 @@ type TriggerOrderType struct {
 	TPSL      TPSL    `json:"tpsl" msgpack:"tpsl"`
 }
 
+// TriggerOrderTypeWire fields are declared in the order the exchange hashes them
 type TriggerOrderTypeWire struct {
-	TriggerPx string `json:"triggerPx" msgpack:"triggerPx"`
 	IsMarket  bool   `json:"isMarket" msgpack:"isMarket"`
+	TriggerPx string `json:"triggerPx" msgpack:"triggerPx"`
 	TPSL      TPSL   `json:"tpsl" msgpack:"tpsl"`
 }
 
@@ -79,8 +210,16 @@ type OrderRequest struct {
 	Cloid      *Cloid    `json:"cloid,omitempty" msgpack:"cloid,omitempty"`
 }
 
+// Side returns the side of the order
+func (o *OrderRequest) Side() Side {
+	return SideFromIsBuy(o.IsBuy)
+}
+
+// Validate checks the order is well formed. A zero size is only accepted for
+// reduce-only trigger orders, the form used by position TP/SL orders that the
+// exchange sizes to the whole position
 func (o *OrderRequest) Validate() error {
-	if o.Size <= 0 {
+	if o.Size < 0 || (o.Size == 0 && !(o.ReduceOnly && o.OrderType.Trigger != nil)) {
 		return errors.New("size must be positive")
 	}
 	if o.LimitPrice <= 0 {
@@ -89,20 +228,56 @@ func (o *OrderRequest) Validate() error {
 	if o.OrderType.Limit == nil && o.OrderType.Trigger == nil {
 		return ErrInvalidOrderType
 	}
-	if o.OrderType.Trigger != nil && o.OrderType.Trigger.TriggerPx <= 0 {
+	if o.OrderType.Limit != nil && o.OrderType.Trigger != nil {
+		return errors.New("order type cannot be both limit and trigger")
+	}
+	if o.OrderType.Limit != nil && !o.OrderType.Limit.TIF.Valid() {
+		return fmt.Errorf("invalid tif %q: must be one of %s, %s, %s", o.OrderType.Limit.TIF, TIFAlo, TIFIoc, TIFGtc)
+	}
+	if o.OrderType.Trigger != nil {
+		return o.validateTrigger()
+	}
+	return nil
+}
+
+// validateTrigger checks that the limit price of a trigger order is on the side of
+// the trigger price where the triggered order can fill: at or above it for buys,
+// at or below it for sells. For market triggers the limit price is the slippage bound
+func (o *OrderRequest) validateTrigger() error {
+	trigger := o.OrderType.Trigger
+	if trigger.TriggerPx <= 0 {
 		return errors.New("trigger price must be positive")
 	}
+	if !trigger.TPSL.Valid() {
+		return fmt.Errorf("%w %q: must be %s or %s", ErrInvalidTPSL, trigger.TPSL, TPSLTakeProfit, TPSLStopLoss)
+	}
+
+	role := "limit price must be marketable once triggered"
+	if trigger.IsMarket {
+		role = "limit price is the slippage bound"
+	}
+
+	if o.IsBuy && o.LimitPrice < trigger.TriggerPx {
+		return fmt.Errorf("%s buy trigger: limit price %v must be at or above trigger price %v (%s)",
+			trigger.TPSL, o.LimitPrice, trigger.TriggerPx, role)
+	}
+	if !o.IsBuy && o.LimitPrice > trigger.TriggerPx {
+		return fmt.Errorf("%s sell trigger: limit price %v must be at or below trigger price %v (%s)",
+			trigger.TPSL, o.LimitPrice, trigger.TriggerPx, role)
+	}
 	return nil
 }
 
+// OrderWire is the wire format of an order. A nil Cloid is left out of both
+// encodings, as the reference SDK does; packing it as nil would change the hash
 type OrderWire struct {
-	Asset      int           `json:"a" msgpack:"a"`           // Asset ID
-	IsBuy      bool          `json:"b" msgpack:"b"`           // Buy/Sell flag
-	Price      string        `json:"p" msgpack:"p"`           // Price as string
-	Size       string        `json:"s" msgpack:"s"`           // Size as string
-	ReduceOnly bool          `json:"r" msgpack:"r"`           // Reduce only flag
-	Type       OrderTypeWire `json:"t" msgpack:"t"`           // Order type
-	Cloid      *string       `json:"c,omitempty" msgpack:"c"` // Client order ID
+	Asset      int           `json:"a" msgpack:"a"`                     // Asset ID
+	IsBuy      bool          `json:"b" msgpack:"b"`                     // Buy/Sell flag
+	Price      string        `json:"p" msgpack:"p"`                     // Price as string
+	Size       string        `json:"s" msgpack:"s"`                     // Size as string
+	ReduceOnly bool          `json:"r" msgpack:"r"`                     // Reduce only flag
+	Type       OrderTypeWire `json:"t" msgpack:"t"`                     // Order type
+	Cloid      *string       `json:"c,omitempty" msgpack:"c,omitempty"` // Client order ID
 }
 
 // GroupingType represents different types of order grouping
@@ -114,6 +289,21 @@ const (
 	GroupingPositionTPSL GroupingType = "positionTpsl"
 )
 
+// ParseGrouping parses an order grouping, case-insensitively
+func ParseGrouping(s string) (GroupingType, error) {
+	for _, grouping := range []GroupingType{GroupingNA, GroupingNormalTPSL, GroupingPositionTPSL} {
+		if strings.EqualFold(s, string(grouping)) {
+			return grouping, nil
+		}
+	}
+	return "", fmt.Errorf("invalid grouping %q: must be one of %s, %s, %s", s, GroupingNA, GroupingNormalTPSL, GroupingPositionTPSL)
+}
+
+// Valid reports whether g is one of the exact values the exchange accepts
+func (g GroupingType) Valid() bool {
+	return g == GroupingNA || g == GroupingNormalTPSL || g == GroupingPositionTPSL
+}
+
 // ModifyRequest represents a request to modify an order
 type ModifyRequest struct {
 	OrderID int64        `json:"oid,omitempty" msgpack:"oid,omitempty"`
@@ -165,6 +355,66 @@ func (c *CancelByCloidRequest) Validate() error {
 	return nil
 }
 
+// CancelWire is the wire format of a cancel by order ID
+type CancelWire struct {
+	Asset   int   `json:"a" msgpack:"a"`
+	OrderID int64 `json:"o" msgpack:"o"`
+}
+
+// CancelByCloidWire is the wire format of a cancel by client order ID
+type CancelByCloidWire struct {
+	Asset int    `json:"asset" msgpack:"asset"`
+	Cloid string `json:"cloid" msgpack:"cloid"`
+}
+
+type CancelAction struct {
+	Type    string       `json:"type" msgpack:"type"`
+	Cancels []CancelWire `json:"cancels" msgpack:"cancels"`
+}
+
+type CancelByCloidAction struct {
+	Type    string              `json:"type" msgpack:"type"`
+	Cancels []CancelByCloidWire `json:"cancels" msgpack:"cancels"`
+}
+
+type BatchModifyAction struct {
+	Type     string       `json:"type" msgpack:"type"`
+	Modifies []ModifyWire `json:"modifies" msgpack:"modifies"`
+}
+
+type UpdateLeverageAction struct {
+	Type     string `json:"type" msgpack:"type"`
+	Asset    int    `json:"asset" msgpack:"asset"`
+	IsCross  bool   `json:"isCross" msgpack:"isCross"`
+	Leverage int    `json:"leverage" msgpack:"leverage"`
+}
+
+// TwapCancelAction cancels a running TWAP order. It is an L1 action
+type TwapCancelAction struct {
+	Type   string `json:"type" msgpack:"type"`
+	Asset  int    `json:"a" msgpack:"a"`
+	TwapID int64  `json:"t" msgpack:"t"`
+}
+
+// SpotUserAction toggles spot dusting, the automatic conversion of tiny spot
+// balances. It is an L1 action; the struct fixes the type, toggleSpotDusting field
+// order the exchange hashes, which sorted map keys would reverse
+type SpotUserAction struct {
+	Type              string            `json:"type" msgpack:"type"`
+	ToggleSpotDusting SpotDustingToggle `json:"toggleSpotDusting" msgpack:"toggleSpotDusting"`
+}
+
+type SpotDustingToggle struct {
+	OptOut bool `json:"optOut" msgpack:"optOut"`
+}
+
+// EvmUserModifyAction selects whether the user's HyperEVM transactions go to big
+// blocks. It is an L1 action
+type EvmUserModifyAction struct {
+	Type           string `json:"type" msgpack:"type"`
+	UsingBigBlocks bool   `json:"usingBigBlocks" msgpack:"usingBigBlocks"`
+}
+
 type OrderAction struct {
 	Type     string       `json:"type" msgpack:"type"`
 	Orders   []OrderWire  `json:"orders" msgpack:"orders"`
@@ -182,9 +432,57 @@ type SignatureType struct {
 
 // Signature represents an ECDSA signature
 type Signature struct {
-	R string `json:"r"`
-	S string `json:"s"`
-	V uint8  `json:"v"`
+	R string `json:"r" msgpack:"r"`
+	S string `json:"s" msgpack:"s"`
+	V uint8  `json:"v" msgpack:"v"`
+}
+
+// MarshalJSON emits the shape the /exchange endpoint expects:
+// {"r":"0x..","s":"0x..","v":27}
+func (s Signature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		R string `json:"r"`
+		S string `json:"s"`
+		V uint8  `json:"v"`
+	}{
+		R: with0xPrefix(s.R),
+		S: with0xPrefix(s.S),
+		V: s.V,
+	})
+}
+
+// UnmarshalJSON accepts v either as a number or as a decimal or 0x-hex string
+func (s *Signature) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		R string          `json:"r"`
+		S string          `json:"s"`
+		V json.RawMessage `json:"v"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	var v uint64
+	var vString string
+	if err := json.Unmarshal(raw.V, &vString); err == nil {
+		parsed, err := strconv.ParseUint(vString, 0, 8)
+		if err != nil {
+			return fmt.Errorf("parsing signature v %q: %w", vString, err)
+		}
+		v = parsed
+	} else if err := json.Unmarshal(raw.V, &v); err != nil || v > 255 {
+		return fmt.Errorf("invalid signature v: %s", raw.V)
+	}
+
+	*s = Signature{R: raw.R, S: raw.S, V: uint8(v)}
+	return nil
+}
+
+func with0xPrefix(h string) string {
+	if h == "" || strings.HasPrefix(h, "0x") || strings.HasPrefix(h, "0X") {
+		return h
+	}
+	return "0x" + h
 }
 
 type Wallet interface {
@@ -249,6 +547,15 @@ var (
 		{Name: "nonce", Type: "uint64"},
 	}
 
+	// AgentWithExpirySignTypes is AgentSignTypes for approvals carrying validUntil
+	AgentWithExpirySignTypes = []SignatureType{
+		{Name: "hyperliquidChain", Type: "string"},
+		{Name: "agentAddress", Type: "address"},
+		{Name: "agentName", Type: "string"},
+		{Name: "nonce", Type: "uint64"},
+		{Name: "validUntil", Type: "uint64"},
+	}
+
 	BuilderFeeSignTypes = []SignatureType{
 		{Name: "hyperliquidChain", Type: "string"},
 		{Name: "maxFeeRate", Type: "string"},
@@ -256,6 +563,26 @@ var (
 		{Name: "nonce", Type: "uint64"},
 	}
 
+	CDepositSignTypes = []SignatureType{
+		{Name: "hyperliquidChain", Type: "string"},
+		{Name: "wei", Type: "uint64"},
+		{Name: "nonce", Type: "uint64"},
+	}
+
+	CWithdrawSignTypes = []SignatureType{
+		{Name: "hyperliquidChain", Type: "string"},
+		{Name: "wei", Type: "uint64"},
+		{Name: "nonce", Type: "uint64"},
+	}
+
+	TokenDelegateSignTypes = []SignatureType{
+		{Name: "hyperliquidChain", Type: "string"},
+		{Name: "validator", Type: "address"},
+		{Name: "wei", Type: "uint64"},
+		{Name: "isUndelegate", Type: "bool"},
+		{Name: "nonce", Type: "uint64"},
+	}
+
 	EIP712DomainFields = []SignatureType{
 		{Name: "name", Type: "string"},
 		{Name: "version", Type: "string"},
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSignatureMarshalJSON(t *testing.T) {
+	sig := Signature{R: "1f", S: "0x2e", V: 27}
+
+	got, err := json.Marshal(sig)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"r":"0x1f","s":"0x2e","v":27}`; string(got) != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+
+	wrapped, err := json.Marshal(map[string]interface{}{"signature": sig})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"signature":{"r":"0x1f","s":"0x2e","v":27}}`; string(wrapped) != want {
+		t.Errorf("Marshal nested = %s, want %s", wrapped, want)
+	}
+}
+
+func TestSignatureUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want uint8
+	}{
+		{"number", `{"r":"0x1f","s":"0x2e","v":28}`, 28},
+		{"decimal string", `{"r":"0x1f","s":"0x2e","v":"27"}`, 27},
+		{"hex string", `{"r":"0x1f","s":"0x2e","v":"0x1c"}`, 28},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sig Signature
+			if err := json.Unmarshal([]byte(tt.json), &sig); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if sig != (Signature{R: "0x1f", S: "0x2e", V: tt.want}) {
+				t.Errorf("Unmarshal = %+v", sig)
+			}
+		})
+	}
+
+	for _, bad := range []string{`{"r":"0x1f","s":"0x2e","v":256}`, `{"r":"0x1f","s":"0x2e","v":"x"}`, `{"r":"0x1f","s":"0x2e","v":-1}`} {
+		var sig Signature
+		if err := json.Unmarshal([]byte(bad), &sig); err == nil {
+			t.Errorf("Unmarshal(%s) = %+v, want error", bad, sig)
+		}
+	}
+}
+
+func TestSignatureJSONRoundTrip(t *testing.T) {
+	sig := Signature{R: "0x1f", S: "0x2e", V: 27}
+
+	data, err := json.Marshal(sig)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got Signature
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != sig {
+		t.Errorf("round trip = %+v, want %+v", got, sig)
+	}
+}
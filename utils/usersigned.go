@@ -0,0 +1,285 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// UserSignedChain holds the chain fields PrepareUserSignedAction adds to a
+// user-signed action. It is embedded in every user-signed action struct and ToMap
+// copies the fields that are set
+type UserSignedChain struct {
+	SignatureChainID string `json:"signatureChainId,omitempty" msgpack:"signatureChainId,omitempty"`
+	HyperliquidChain string `json:"hyperliquidChain,omitempty" msgpack:"hyperliquidChain,omitempty"`
+}
+
+// addTo copies the chain fields that are set into m and returns it
+func (c UserSignedChain) addTo(m map[string]interface{}) map[string]interface{} {
+	if c.SignatureChainID != "" {
+		m["signatureChainId"] = c.SignatureChainID
+	}
+	if c.HyperliquidChain != "" {
+		m["hyperliquidChain"] = c.HyperliquidChain
+	}
+	return m
+}
+
+// The user-signed action structs below back the Create*Action maps, which are
+// their ToMap form. The signing functions take maps, so sign ToMap(): the typed
+// data is encoded with sorted keys and the struct field order plays no part in it
+
+// USDTransferAction is the usdSend action
+type USDTransferAction struct {
+	Destination string `json:"destination" msgpack:"destination"`
+	Amount      string `json:"amount" msgpack:"amount"`
+	Time        uint64 `json:"time" msgpack:"time"`
+	Type        string `json:"type" msgpack:"type"`
+	UserSignedChain
+}
+
+func CreateUSDTransferActionStruct(destination string, amount string, timestamp uint64) USDTransferAction {
+	return USDTransferAction{
+		Destination: strings.ToLower(destination),
+		Amount:      amount,
+		Time:        timestamp,
+		Type:        "usdSend",
+	}
+}
+
+func (a USDTransferAction) ToMap() map[string]interface{} {
+	return a.addTo(map[string]interface{}{
+		"destination": a.Destination,
+		"amount":      a.Amount,
+		"time":        a.Time,
+		"type":        a.Type,
+	})
+}
+
+// SpotTransferAction is the spotSend action
+type SpotTransferAction struct {
+	Destination string `json:"destination" msgpack:"destination"`
+	Amount      string `json:"amount" msgpack:"amount"`
+	Token       string `json:"token" msgpack:"token"`
+	Time        uint64 `json:"time" msgpack:"time"`
+	Type        string `json:"type" msgpack:"type"`
+	UserSignedChain
+}
+
+func CreateSpotTransferActionStruct(destination string, token string, amount string, timestamp uint64) SpotTransferAction {
+	return SpotTransferAction{
+		Destination: strings.ToLower(destination),
+		Amount:      amount,
+		Token:       token,
+		Time:        timestamp,
+		Type:        "spotSend",
+	}
+}
+
+func (a SpotTransferAction) ToMap() map[string]interface{} {
+	return a.addTo(map[string]interface{}{
+		"destination": a.Destination,
+		"amount":      a.Amount,
+		"token":       a.Token,
+		"time":        a.Time,
+		"type":        a.Type,
+	})
+}
+
+// WithdrawAction is the withdraw3 action
+type WithdrawAction struct {
+	Destination string `json:"destination" msgpack:"destination"`
+	Amount      string `json:"amount" msgpack:"amount"`
+	Time        uint64 `json:"time" msgpack:"time"`
+	Type        string `json:"type" msgpack:"type"`
+	UserSignedChain
+}
+
+func CreateWithdrawActionStruct(destination string, amount string, timestamp uint64) WithdrawAction {
+	return WithdrawAction{
+		Destination: strings.ToLower(destination),
+		Amount:      amount,
+		Time:        timestamp,
+		Type:        "withdraw3",
+	}
+}
+
+func (a WithdrawAction) ToMap() map[string]interface{} {
+	return a.addTo(map[string]interface{}{
+		"destination": a.Destination,
+		"amount":      a.Amount,
+		"time":        a.Time,
+		"type":        a.Type,
+	})
+}
+
+// USDClassTransferAction is the usdClassTransfer action
+type USDClassTransferAction struct {
+	Type   string `json:"type" msgpack:"type"`
+	Amount string `json:"amount" msgpack:"amount"`
+	ToPerp bool   `json:"toPerp" msgpack:"toPerp"`
+	Nonce  uint64 `json:"nonce" msgpack:"nonce"`
+	UserSignedChain
+}
+
+func CreateUSDClassTransferActionStruct(amount string, toPerp bool, nonce uint64) USDClassTransferAction {
+	return USDClassTransferAction{
+		Type:   "usdClassTransfer",
+		Amount: amount,
+		ToPerp: toPerp,
+		Nonce:  nonce,
+	}
+}
+
+func (a USDClassTransferAction) ToMap() map[string]interface{} {
+	return a.addTo(map[string]interface{}{
+		"type":   a.Type,
+		"amount": a.Amount,
+		"toPerp": a.ToPerp,
+		"nonce":  a.Nonce,
+	})
+}
+
+// ApproveAgentAction is the approveAgent action. ValidUntil is only set for agents
+// created with CreateAgentActionWithExpiryStruct
+type ApproveAgentAction struct {
+	Type         string  `json:"type" msgpack:"type"`
+	AgentAddress string  `json:"agentAddress" msgpack:"agentAddress"`
+	AgentName    string  `json:"agentName" msgpack:"agentName"`
+	Nonce        uint64  `json:"nonce" msgpack:"nonce"`
+	ValidUntil   *uint64 `json:"validUntil,omitempty" msgpack:"validUntil,omitempty"`
+	UserSignedChain
+}
+
+func CreateAgentActionStruct(agentAddress string, agentName string, nonce uint64) ApproveAgentAction {
+	return ApproveAgentAction{
+		Type:         "approveAgent",
+		AgentAddress: strings.ToLower(agentAddress),
+		AgentName:    agentName,
+		Nonce:        nonce,
+	}
+}
+
+func CreateAgentActionWithExpiryStruct(agentAddress string, agentName string, nonce uint64, validUntil uint64) ApproveAgentAction {
+	action := CreateAgentActionStruct(agentAddress, agentName, nonce)
+	action.ValidUntil = &validUntil
+	return action
+}
+
+func (a ApproveAgentAction) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"type":         a.Type,
+		"agentAddress": a.AgentAddress,
+		"agentName":    a.AgentName,
+		"nonce":        a.Nonce,
+	}
+	if a.ValidUntil != nil {
+		m["validUntil"] = *a.ValidUntil
+	}
+	return a.addTo(m)
+}
+
+// ApproveBuilderFeeAction is the approveBuilderFee action
+type ApproveBuilderFeeAction struct {
+	MaxFeeRate string `json:"maxFeeRate" msgpack:"maxFeeRate"`
+	Builder    string `json:"builder" msgpack:"builder"`
+	Nonce      uint64 `json:"nonce" msgpack:"nonce"`
+	Type       string `json:"type" msgpack:"type"`
+	UserSignedChain
+}
+
+func CreateApproveBuilderFeeActionStruct(maxFeeRate string, builder string, nonce uint64) ApproveBuilderFeeAction {
+	return ApproveBuilderFeeAction{
+		MaxFeeRate: maxFeeRate,
+		Builder:    strings.ToLower(builder),
+		Nonce:      nonce,
+		Type:       "approveBuilderFee",
+	}
+}
+
+func (a ApproveBuilderFeeAction) ToMap() map[string]interface{} {
+	return a.addTo(map[string]interface{}{
+		"maxFeeRate": a.MaxFeeRate,
+		"builder":    a.Builder,
+		"nonce":      a.Nonce,
+		"type":       a.Type,
+	})
+}
+
+// StakingTransferAction is the cDeposit or cWithdraw action, moving Wei between
+// the spot balance and staking
+type StakingTransferAction struct {
+	Type  string `json:"type" msgpack:"type"`
+	Wei   uint64 `json:"wei" msgpack:"wei"`
+	Nonce uint64 `json:"nonce" msgpack:"nonce"`
+	UserSignedChain
+}
+
+// CreateCDepositActionStruct is CreateCDepositAction as a StakingTransferAction
+func CreateCDepositActionStruct(amount float64, nonce uint64) (StakingTransferAction, error) {
+	wei, err := HypeToWei(amount)
+	if err != nil {
+		return StakingTransferAction{}, fmt.Errorf("converting deposit amount: %w", err)
+	}
+
+	return StakingTransferAction{Type: "cDeposit", Wei: uint64(wei), Nonce: nonce}, nil
+}
+
+// CreateCWithdrawActionStruct is CreateCWithdrawAction as a StakingTransferAction
+func CreateCWithdrawActionStruct(amount float64, nonce uint64) (StakingTransferAction, error) {
+	wei, err := HypeToWei(amount)
+	if err != nil {
+		return StakingTransferAction{}, fmt.Errorf("converting withdraw amount: %w", err)
+	}
+
+	return StakingTransferAction{Type: "cWithdraw", Wei: uint64(wei), Nonce: nonce}, nil
+}
+
+func (a StakingTransferAction) ToMap() map[string]interface{} {
+	return a.addTo(map[string]interface{}{
+		"type":  a.Type,
+		"wei":   a.Wei,
+		"nonce": a.Nonce,
+	})
+}
+
+// TokenDelegateAction is the tokenDelegate action
+type TokenDelegateAction struct {
+	Validator    string `json:"validator" msgpack:"validator"`
+	Wei          uint64 `json:"wei" msgpack:"wei"`
+	IsUndelegate bool   `json:"isUndelegate" msgpack:"isUndelegate"`
+	Nonce        uint64 `json:"nonce" msgpack:"nonce"`
+	Type         string `json:"type" msgpack:"type"`
+	UserSignedChain
+}
+
+// CreateTokenDelegateActionStruct is CreateTokenDelegateAction as a TokenDelegateAction
+func CreateTokenDelegateActionStruct(validator string, amount float64, isUndelegate bool, nonce uint64) (TokenDelegateAction, error) {
+	if !common.IsHexAddress(validator) {
+		return TokenDelegateAction{}, fmt.Errorf("invalid validator address: %s", validator)
+	}
+
+	wei, err := HypeToWei(amount)
+	if err != nil {
+		return TokenDelegateAction{}, fmt.Errorf("converting delegation amount: %w", err)
+	}
+
+	return TokenDelegateAction{
+		Validator:    strings.ToLower(validator),
+		Wei:          uint64(wei),
+		IsUndelegate: isUndelegate,
+		Nonce:        nonce,
+		Type:         "tokenDelegate",
+	}, nil
+}
+
+func (a TokenDelegateAction) ToMap() map[string]interface{} {
+	return a.addTo(map[string]interface{}{
+		"validator":    a.Validator,
+		"wei":          a.Wei,
+		"isUndelegate": a.IsUndelegate,
+		"nonce":        a.Nonce,
+		"type":         a.Type,
+	})
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUserSignedActionStructsMatchMapConstructors(t *testing.T) {
+	const (
+		destination = "0x1719884EB866cb12b2287399B15f7db5e7D775EA"
+		timestamp   = fixtureNonce
+	)
+	wallet := testWallet(t)
+
+	must := func(m map[string]interface{}, err error) map[string]interface{} {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	cDeposit, err := CreateCDepositActionStruct(1.5, timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cWithdraw, err := CreateCWithdrawActionStruct(1.5, timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delegate, err := CreateTokenDelegateActionStruct(destination, 2, true, timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		fromStruct map[string]interface{}
+		fromMap    map[string]interface{}
+	}{
+		{"usdSend", CreateUSDTransferActionStruct(destination, "1.5", timestamp).ToMap(), CreateUSDTransferAction(destination, "1.5", timestamp)},
+		{"spotSend", CreateSpotTransferActionStruct(destination, "PURR:0xc1fb593aeffbeb02f85e0308e9956a90", "10", timestamp).ToMap(), CreateSpotTransferAction(destination, "PURR:0xc1fb593aeffbeb02f85e0308e9956a90", "10", timestamp)},
+		{"withdraw3", CreateWithdrawActionStruct(destination, "5", timestamp).ToMap(), CreateWithdrawAction(destination, "5", timestamp)},
+		{"usdClassTransfer", CreateUSDClassTransferActionStruct("3", true, timestamp).ToMap(), CreateUSDClassTransferAction("3", true, timestamp)},
+		{"approveAgent", CreateAgentActionStruct(destination, "bot", timestamp).ToMap(), CreateAgentAction(destination, "bot", timestamp)},
+		{"approveAgent with expiry", CreateAgentActionWithExpiryStruct(destination, "bot", timestamp, timestamp+60000).ToMap(), CreateAgentActionWithExpiry(destination, "bot", timestamp, timestamp+60000)},
+		{"approveBuilderFee", CreateApproveBuilderFeeActionStruct("0.001%", destination, timestamp).ToMap(), CreateApproveBuilderFeeAction("0.001%", destination, timestamp)},
+		{"cDeposit", cDeposit.ToMap(), must(CreateCDepositAction(1.5, timestamp))},
+		{"cWithdraw", cWithdraw.ToMap(), must(CreateCWithdrawAction(1.5, timestamp))},
+		{"tokenDelegate", delegate.ToMap(), must(CreateTokenDelegateAction(destination, 2, true, timestamp))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, isMainnet := range []bool{true, false} {
+				got, err := MarshalAction(PrepareUserSignedAction(tt.fromStruct, isMainnet))
+				if err != nil {
+					t.Fatalf("MarshalAction(struct): %v", err)
+				}
+				want, err := MarshalAction(PrepareUserSignedAction(tt.fromMap, isMainnet))
+				if err != nil {
+					t.Fatalf("MarshalAction(map): %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("mainnet=%v action bytes\n got %x\nwant %x", isMainnet, got, want)
+				}
+
+				actionType, ok := LookupUserSignedActionType(tt.fromMap["type"].(string))
+				if !ok {
+					t.Fatalf("no registered encoding")
+				}
+				gotDigest, err := UserSignedActionSigningHash(tt.fromStruct, actionType.PayloadTypes, actionType.PrimaryType, isMainnet)
+				if err != nil {
+					t.Fatalf("UserSignedActionSigningHash(struct): %v", err)
+				}
+				wantDigest, err := UserSignedActionSigningHash(tt.fromMap, actionType.PayloadTypes, actionType.PrimaryType, isMainnet)
+				if err != nil {
+					t.Fatalf("UserSignedActionSigningHash(map): %v", err)
+				}
+				if !bytes.Equal(gotDigest, wantDigest) {
+					t.Errorf("mainnet=%v digest %x, want %x", isMainnet, gotDigest, wantDigest)
+				}
+
+				gotSig, err := SignUserSignedAction(wallet, tt.fromStruct, actionType.PayloadTypes, actionType.PrimaryType, isMainnet)
+				if err != nil {
+					t.Fatalf("SignUserSignedAction(struct): %v", err)
+				}
+				wantSig, err := SignUserSignedAction(wallet, tt.fromMap, actionType.PayloadTypes, actionType.PrimaryType, isMainnet)
+				if err != nil {
+					t.Fatalf("SignUserSignedAction(map): %v", err)
+				}
+				if gotSig != wantSig {
+					t.Errorf("mainnet=%v signature %+v, want %+v", isMainnet, gotSig, wantSig)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// PrivateKeyWallet is a Wallet backed by an in-memory secp256k1 private key
+type PrivateKeyWallet struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewPrivateKeyWallet creates a wallet from a hex-encoded private key, with or
+// without the 0x prefix
+func NewPrivateKeyWallet(hexKey string) (*PrivateKeyWallet, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+
+	return &PrivateKeyWallet{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}, nil
+}
+
+// SignMessage signs the Ethereum signed message hash of message (see HashMessage),
+// returning V as 27 or 28. Signing is deterministic (RFC 6979): the same key and
+// message always produce the same signature
+func (w *PrivateKeyWallet) SignMessage(message []byte) (Signature, error) {
+	sig, err := crypto.Sign(HashMessage(message), w.key)
+	if err != nil {
+		return Signature{}, fmt.Errorf("signing message: %w", err)
+	}
+
+	return Signature{
+		R: hexutil.Encode(sig[:32]),
+		S: hexutil.Encode(sig[32:64]),
+		V: sig[64] + 27,
+	}, nil
+}
+
+func (w *PrivateKeyWallet) Address() common.Address {
+	return w.address
+}
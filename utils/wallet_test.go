@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestPrivateKeyWalletAddress(t *testing.T) {
+	if got, want := testWallet(t).Address().Hex(), "0x14791697260E4c9A71f18484C9f997B308e59325"; got != want {
+		t.Errorf("Address = %s, want %s", got, want)
+	}
+}
+
+func TestPrivateKeyWalletGoldenSignatures(t *testing.T) {
+	wallet := testWallet(t)
+
+	encodedOrder, err := encodeL1TypedData(fixtureOrderAction(t, nil), "", fixtureNonce, nil, true)
+	if err != nil {
+		t.Fatalf("encodeL1TypedData: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		message []byte
+		want    Signature
+	}{
+		{
+			name:    "hello",
+			message: []byte("hello"),
+			want: Signature{
+				R: "0x0079d91efaa7ca55074617a2c255a8855bc278a3e0ca1b837f60ce000109ef85",
+				S: "0x40960e193be95e38fb2f5307ca0c20108fcc420ff49e48b9a8cb11d9074deb46",
+				V: 27,
+			},
+		},
+		{
+			name:    "mainnet order typed data",
+			message: encodedOrder,
+			want: Signature{
+				R: "0x8d22794d1f50dc5da3bab70b7b9b1220a0a27972324ff3bad71c1c05efd9b562",
+				S: "0x2a097c61e96f9f332c9c7271942d72e857b6414f5c21685d8dd932b5b60aa76a",
+				V: 28,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				sig, err := wallet.SignMessage(tt.message)
+				if err != nil {
+					t.Fatalf("SignMessage: %v", err)
+				}
+				if sig != tt.want {
+					t.Fatalf("signature %d = %+v, want %+v", i, sig, tt.want)
+				}
+			}
+
+			ok, err := VerifySignature(wallet.Address().Hex(), tt.message, tt.want)
+			if err != nil || !ok {
+				t.Errorf("VerifySignature = %v, %v", ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,694 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cgaspart/hyperliquid-go/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	MainnetWSURL = "wss://api.hyperliquid.xyz/ws"
+	TestnetWSURL = "wss://api.hyperliquid-testnet.xyz/ws"
+
+	wsPingInterval        = 50 * time.Second
+	wsChannelBuffer       = 100
+	wsDedupeWindow        = 64
+	defaultReconnectDelay = 500 * time.Millisecond
+	defaultReconnectMax   = 30 * time.Second
+	defaultReconnectTries = 10
+	wsPostTimeout         = 30 * time.Second
+)
+
+var (
+	ErrWSClosed          = errors.New("websocket client closed")
+	ErrWSReconnectFailed = errors.New("websocket reconnection failed")
+	ErrWSNotConnected    = errors.New("websocket not connected")
+	ErrWSPostInterrupted = errors.New("websocket connection lost before post response")
+)
+
+// ConnectionState describes the state of the underlying WebSocket connection
+type ConnectionState int
+
+const (
+	StateConnected ConnectionState = iota
+	StateDisconnected
+	StateFailed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("ConnectionState(%d)", int(s))
+}
+
+// WSClient streams subscription data from the WebSocket API, decoded into the
+// utils types the Info client returns. Subscriptions survive reconnects: after the
+// connection drops it is re-established with exponential backoff and every active
+// subscription is replayed. When reconnection gives up, StateFailed is published,
+// every subscription channel is closed and Err reports why
+type WSClient struct {
+	url    string
+	dialer *websocket.Dialer
+
+	reconnectDelay    time.Duration
+	reconnectMaxDelay time.Duration
+	reconnectAttempts int
+
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	subs   map[string]*wsSubscription
+	closed bool
+	err    error
+	states chan ConnectionState
+	errs   chan error
+
+	pending    map[uint64]chan json.RawMessage // post responses awaited, by request id
+	nextPostID uint64
+
+	writeMu sync.Mutex
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+type wsSubscription struct {
+	request map[string]interface{}
+	deliver func(data json.RawMessage) error // decodes data and sends it without blocking
+	close   func()
+	recent  []uint64 // hashes of the last delivered messages, oldest first
+}
+
+// newWSSubscription creates a subscription whose messages are decoded by decode
+// and delivered on the returned channel
+func newWSSubscription[T any](request map[string]interface{}, decode func([]byte) (T, error)) (*wsSubscription, <-chan T) {
+	ch := make(chan T, wsChannelBuffer)
+	sub := &wsSubscription{
+		request: request,
+		deliver: func(data json.RawMessage) error {
+			v, err := decode(data)
+			if err != nil {
+				return err
+			}
+			select {
+			case ch <- v:
+			default:
+			}
+			return nil
+		},
+		close: func() { close(ch) },
+	}
+	return sub, ch
+}
+
+type wsMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewWSClient creates a WebSocket client. An empty url defaults to MainnetWSURL
+func NewWSClient(url string) *WSClient {
+	if url == "" {
+		url = MainnetWSURL
+	}
+
+	return &WSClient{
+		url:               url,
+		dialer:            websocket.DefaultDialer,
+		reconnectDelay:    defaultReconnectDelay,
+		reconnectMaxDelay: defaultReconnectMax,
+		reconnectAttempts: defaultReconnectTries,
+		subs:              make(map[string]*wsSubscription),
+		pending:           make(map[uint64]chan json.RawMessage),
+		states:            make(chan ConnectionState, wsChannelBuffer),
+		errs:              make(chan error, wsChannelBuffer),
+		done:              make(chan struct{}),
+	}
+}
+
+// SetReconnectPolicy configures the backoff used after a dropped connection: the
+// first retry waits initialDelay, each following one doubles it up to maxDelay, and
+// the client fails permanently after maxAttempts consecutive failed dials.
+// It must be called before Connect
+func (c *WSClient) SetReconnectPolicy(maxAttempts int, initialDelay, maxDelay time.Duration) {
+	c.reconnectAttempts = maxAttempts
+	c.reconnectDelay = initialDelay
+	c.reconnectMaxDelay = maxDelay
+}
+
+// States publishes connection state transitions. Transitions are dropped if the
+// channel is not drained; it is closed together with the client
+func (c *WSClient) States() <-chan ConnectionState {
+	return c.states
+}
+
+// Errors publishes subscription messages that could not be decoded. Errors are
+// dropped if the channel is not drained; it is closed together with the client
+func (c *WSClient) Errors() <-chan error {
+	return c.errs
+}
+
+// Err returns the reason the client stopped, or nil while it is running
+func (c *WSClient) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Connect dials the server and starts the read and keepalive loops
+func (c *WSClient) Connect(ctx context.Context) error {
+	conn, _, err := c.dialer.DialContext(ctx, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("dialing %s: %w", c.url, err)
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		conn.Close()
+		return ErrWSClosed
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	if err := c.resubscribe(conn); err != nil {
+		conn.Close()
+		return err
+	}
+	c.publishState(StateConnected)
+
+	c.wg.Add(2)
+	go c.readLoop(conn)
+	go c.pingLoop()
+
+	return nil
+}
+
+// Close unsubscribes nothing server-side; it tears down the connection and closes
+// every subscription channel
+func (c *WSClient) Close() error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if !c.shutdown(ErrWSClosed) {
+		c.wg.Wait()
+		return nil
+	}
+
+	var err error
+	if conn != nil {
+		c.writeMu.Lock()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		c.writeMu.Unlock()
+		err = conn.Close()
+	}
+
+	c.wg.Wait()
+	return err
+}
+
+// shutdown stops the client with reason, closing every subscription channel and
+// the state channel. It reports false if the client was already stopped
+func (c *WSClient) shutdown(reason error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.closed = true
+	c.err = reason
+	close(c.done)
+
+	for key, sub := range c.subs {
+		sub.close()
+		delete(c.subs, key)
+	}
+	if errors.Is(reason, ErrWSReconnectFailed) {
+		c.sendState(StateFailed)
+	}
+	close(c.states)
+	close(c.errs)
+
+	return true
+}
+
+func (c *WSClient) publishState(state ConnectionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.sendState(state)
+	}
+}
+
+// sendState must be called with c.mu held
+func (c *WSClient) sendState(state ConnectionState) {
+	select {
+	case c.states <- state:
+	default:
+	}
+}
+
+// SubscribeL2Book streams order book snapshots for coin
+func (c *WSClient) SubscribeL2Book(coin string) (<-chan utils.L2Book, error) {
+	if coin == "" {
+		return nil, fmt.Errorf("coin must be specified")
+	}
+	sub, ch := newWSSubscription(map[string]interface{}{"type": "l2Book", "coin": coin}, utils.ParseL2Book)
+	if err := c.subscribe("l2Book:"+coin, sub); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SubscribeTrades streams public trades for coin
+func (c *WSClient) SubscribeTrades(coin string) (<-chan []utils.Trade, error) {
+	if coin == "" {
+		return nil, fmt.Errorf("coin must be specified")
+	}
+	sub, ch := newWSSubscription(map[string]interface{}{"type": "trades", "coin": coin}, utils.ParseTrades)
+	if err := c.subscribe("trades:"+coin, sub); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SubscribeUserFills streams the fills of addr. The first message after each
+// (re)connect is a snapshot of recent fills
+func (c *WSClient) SubscribeUserFills(addr string) (<-chan []utils.Fill, error) {
+	if !common.IsHexAddress(addr) {
+		return nil, fmt.Errorf("invalid address: %s", addr)
+	}
+	sub, ch := newWSSubscription(map[string]interface{}{"type": "userFills", "user": addr}, parseUserFillsMessage)
+	if err := c.subscribe("userFills:"+strings.ToLower(addr), sub); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SubscribeOrderUpdates streams order status updates of addr. The server does not
+// tag these messages with the user, so only one address can be followed per client
+func (c *WSClient) SubscribeOrderUpdates(addr string) (<-chan []utils.OrderUpdate, error) {
+	if !common.IsHexAddress(addr) {
+		return nil, fmt.Errorf("invalid address: %s", addr)
+	}
+	sub, ch := newWSSubscription(map[string]interface{}{"type": "orderUpdates", "user": addr}, utils.ParseOrderUpdates)
+	if err := c.subscribe("orderUpdates", sub); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// parseUserFillsMessage decodes the fills of a userFills message,
+// {"isSnapshot": .., "user": .., "fills": [..]}
+func parseUserFillsMessage(data []byte) ([]utils.Fill, error) {
+	var msg struct {
+		Fills json.RawMessage `json:"fills"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("decoding user fills: %w", err)
+	}
+	return utils.ParseFills(msg.Fills)
+}
+
+func (c *WSClient) subscribe(key string, sub *wsSubscription) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrWSClosed
+	}
+	if _, ok := c.subs[key]; ok {
+		c.mu.Unlock()
+		return fmt.Errorf("already subscribed to %s", key)
+	}
+	c.subs[key] = sub
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn != nil {
+		if err := c.writeJSON(conn, subscribeMessage(sub.request)); err != nil {
+			// Unregister so the caller can retry; shutdown may already have closed it
+			c.mu.Lock()
+			if c.subs[key] == sub {
+				delete(c.subs, key)
+				sub.close()
+			}
+			c.mu.Unlock()
+			return fmt.Errorf("sending subscription: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func subscribeMessage(request map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"method":       "subscribe",
+		"subscription": request,
+	}
+}
+
+// resubscribe replays every active subscription on conn
+func (c *WSClient) resubscribe(conn *websocket.Conn) error {
+	c.mu.Lock()
+	requests := make([]map[string]interface{}, 0, len(c.subs))
+	for _, sub := range c.subs {
+		requests = append(requests, sub.request)
+	}
+	c.mu.Unlock()
+
+	for _, request := range requests {
+		if err := c.writeJSON(conn, subscribeMessage(request)); err != nil {
+			return fmt.Errorf("replaying subscription: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *WSClient) writeJSON(conn *websocket.Conn, v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteJSON(v)
+}
+
+func (c *WSClient) readLoop(conn *websocket.Conn) {
+	defer c.wg.Done()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			conn.Close()
+			c.failPending()
+			c.publishState(StateDisconnected)
+			if conn = c.reconnect(); conn == nil {
+				return
+			}
+			c.publishState(StateConnected)
+			continue
+		}
+
+		c.dispatch(data)
+	}
+}
+
+// reconnect re-dials with exponential backoff until it succeeds, the client is
+// closed or the attempts are exhausted, in which case the client is shut down
+func (c *WSClient) reconnect() *websocket.Conn {
+	delay := c.reconnectDelay
+	var lastErr error
+
+	for attempt := 0; attempt < c.reconnectAttempts; attempt++ {
+		select {
+		case <-c.done:
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > c.reconnectMaxDelay {
+			delay = c.reconnectMaxDelay
+		}
+
+		conn, _, err := c.dialer.Dial(c.url, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		c.conn = conn
+		c.mu.Unlock()
+
+		if err := c.resubscribe(conn); err != nil {
+			lastErr = err
+			conn.Close()
+			continue
+		}
+		return conn
+	}
+
+	c.shutdown(fmt.Errorf("%w after %d attempts: %v", ErrWSReconnectFailed, c.reconnectAttempts, lastErr))
+	return nil
+}
+
+func (c *WSClient) pingLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			conn := c.conn
+			c.mu.Unlock()
+			if conn != nil {
+				_ = c.writeJSON(conn, map[string]string{"method": "ping"})
+			}
+		}
+	}
+}
+
+// dispatch decodes a message onto its subscription channel. Messages identical to
+// one recently delivered on the same subscription (typically snapshots replayed
+// after a reconnect) are skipped, messages that fail to decode are reported on
+// Errors, and messages are dropped when the subscriber is not keeping up rather
+// than stalling the read loop
+func (c *WSClient) dispatch(data []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	if msg.Channel == "post" {
+		c.dispatchPost(msg.Data)
+		return
+	}
+
+	key, ok := messageKey(msg)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sub, ok := c.subs[key]
+	if !ok || c.closed {
+		return
+	}
+
+	hasher := fnv.New64a()
+	hasher.Write(msg.Data)
+	sum := hasher.Sum64()
+	for _, seen := range sub.recent {
+		if seen == sum {
+			return
+		}
+	}
+	if len(sub.recent) == wsDedupeWindow {
+		sub.recent = sub.recent[1:]
+	}
+	sub.recent = append(sub.recent, sum)
+
+	if err := sub.deliver(msg.Data); err != nil {
+		select {
+		case c.errs <- fmt.Errorf("%s message: %w", key, err):
+		default:
+		}
+	}
+}
+
+// Post submits a signed action over the WebSocket instead of POSTing it to
+// /exchange. It is PostSigned for an action without a vault address
+func (c *WSClient) Post(ctx context.Context, action interface{}, signature utils.Signature, nonce uint64) (utils.OrderResponse, error) {
+	return c.PostSigned(ctx, utils.SignedAction{
+		Action:    action,
+		Nonce:     nonce,
+		Signature: signature,
+	})
+}
+
+// PostSigned submits signed over the WebSocket and waits for the response carrying
+// the same request id, which is parsed like an /exchange response so rejections
+// surface the same errors. The wait ends when ctx is done, after wsPostTimeout, or
+// with ErrWSPostInterrupted if the connection drops first; the action may still
+// have been executed in the latter two cases
+func (c *WSClient) PostSigned(ctx context.Context, signed utils.SignedAction) (utils.OrderResponse, error) {
+	payload := signed.ToPayload()
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return utils.OrderResponse{}, fmt.Errorf("encoding request: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return utils.OrderResponse{}, ErrWSClosed
+	}
+	conn := c.conn
+	if conn == nil {
+		c.mu.Unlock()
+		return utils.OrderResponse{}, ErrWSNotConnected
+	}
+	c.nextPostID++
+	id := c.nextPostID
+	ch := make(chan json.RawMessage, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	request := map[string]interface{}{
+		"method": "post",
+		"id":     id,
+		"request": map[string]interface{}{
+			"type":    "action",
+			"payload": json.RawMessage(encoded),
+		},
+	}
+	if err := c.writeJSON(conn, request); err != nil {
+		return utils.OrderResponse{}, fmt.Errorf("sending post request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wsPostTimeout)
+	defer cancel()
+
+	var data json.RawMessage
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return utils.OrderResponse{}, ErrWSPostInterrupted
+		}
+		data = resp
+	case <-c.done:
+		return utils.OrderResponse{}, ErrWSClosed
+	case <-ctx.Done():
+		return utils.OrderResponse{}, fmt.Errorf("waiting for post response %d: %w", id, ctx.Err())
+	}
+
+	resp, err := parsePostResponse(data)
+	resp.Payload = encoded
+
+	return resp, err
+}
+
+// wsPostResponse is the data of a "post" channel message
+type wsPostResponse struct {
+	ID       uint64 `json:"id"`
+	Response struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	} `json:"response"`
+}
+
+// dispatchPost hands a post response to the request waiting for its id
+func (c *WSClient) dispatchPost(data []byte) {
+	var resp struct {
+		ID uint64 `json:"id"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	ch, ok := c.pending[resp.ID]
+	delete(c.pending, resp.ID)
+	c.mu.Unlock()
+
+	if ok {
+		ch <- data
+	}
+}
+
+// failPending wakes every request still waiting for a post response with
+// ErrWSPostInterrupted, since responses are not replayed after a reconnect
+func (c *WSClient) failPending() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+// parsePostResponse parses a post response like an /exchange response. An "error"
+// response carries the rejection message the HTTP API would return with status "err"
+func parsePostResponse(data []byte) (utils.OrderResponse, error) {
+	var msg wsPostResponse
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return utils.OrderResponse{}, fmt.Errorf("decoding post response: %w", err)
+	}
+
+	body := []byte(msg.Response.Payload)
+	if msg.Response.Type == "error" {
+		wrapped, err := json.Marshal(map[string]json.RawMessage{
+			"status":   json.RawMessage(`"err"`),
+			"response": msg.Response.Payload,
+		})
+		if err != nil {
+			return utils.OrderResponse{}, fmt.Errorf("decoding post response: %w", err)
+		}
+		body = wrapped
+	}
+
+	return utils.ParseOrderResponse(body)
+}
+
+// messageKey derives the subscription key a message belongs to
+func messageKey(msg wsMessage) (string, bool) {
+	switch msg.Channel {
+	case "l2Book":
+		var book struct {
+			Coin string `json:"coin"`
+		}
+		if err := json.Unmarshal(msg.Data, &book); err != nil {
+			return "", false
+		}
+		return "l2Book:" + book.Coin, true
+	case "trades":
+		var trades []struct {
+			Coin string `json:"coin"`
+		}
+		if err := json.Unmarshal(msg.Data, &trades); err != nil || len(trades) == 0 {
+			return "", false
+		}
+		return "trades:" + trades[0].Coin, true
+	case "userFills":
+		var fills struct {
+			User string `json:"user"`
+		}
+		if err := json.Unmarshal(msg.Data, &fills); err != nil {
+			return "", false
+		}
+		return "userFills:" + strings.ToLower(fills.User), true
+	case "orderUpdates":
+		return "orderUpdates", true
+	}
+	return "", false
+}
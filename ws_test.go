@@ -0,0 +1,139 @@
+package hyperliquid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWSDispatchDecodesL2Book(t *testing.T) {
+	c := NewWSClient("")
+	books, err := c.SubscribeL2Book("BTC")
+	if err != nil {
+		t.Fatalf("SubscribeL2Book: %v", err)
+	}
+
+	c.dispatch([]byte(`{"channel":"l2Book","data":{"coin":"BTC","time":1700000000000,"levels":[[{"px":"60000.5","sz":"1.2","n":3}],[{"px":"60001","sz":"0.5","n":1}]]}}`))
+
+	select {
+	case book := <-books:
+		if book.Coin != "BTC" || book.Time != 1700000000000 {
+			t.Fatalf("book = %+v", book)
+		}
+		bid, ok := book.BestBid()
+		if !ok || bid.Px != 60000.5 || bid.Sz != 1.2 || bid.N != 3 {
+			t.Fatalf("best bid = %+v, %v", bid, ok)
+		}
+	default:
+		t.Fatal("no book delivered")
+	}
+}
+
+func TestWSDispatchReportsDecodeErrors(t *testing.T) {
+	c := NewWSClient("")
+	books, err := c.SubscribeL2Book("BTC")
+	if err != nil {
+		t.Fatalf("SubscribeL2Book: %v", err)
+	}
+
+	c.dispatch([]byte(`{"channel":"l2Book","data":{"coin":"BTC","time":1,"levels":[[{"px":"abc","sz":"1","n":1}],[]]}}`))
+
+	select {
+	case book := <-books:
+		t.Fatalf("undecodable message delivered: %+v", book)
+	default:
+	}
+	select {
+	case err := <-c.Errors():
+		if err == nil {
+			t.Fatal("nil error")
+		}
+	default:
+		t.Fatal("decode error not reported")
+	}
+}
+
+func TestWSDispatchDecodesUserFills(t *testing.T) {
+	const user = "0x1719884eb866cb12b2287399b15f7db5e7d775ea"
+	c := NewWSClient("")
+	fills, err := c.SubscribeUserFills(user)
+	if err != nil {
+		t.Fatalf("SubscribeUserFills: %v", err)
+	}
+
+	c.dispatch([]byte(`{"channel":"userFills","data":{"isSnapshot":true,"user":"` + user + `","fills":[]}}`))
+
+	select {
+	case got := <-fills:
+		if len(got) != 0 {
+			t.Fatalf("fills = %+v", got)
+		}
+	default:
+		t.Fatal("no fills delivered")
+	}
+}
+
+// dialTestServer returns a client connection to a server that accepts WebSocket
+// connections and discards what it reads
+func dialTestServer(t *testing.T) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dialing test server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWSSubscribeRetryAfterWriteFailure(t *testing.T) {
+	c := NewWSClient("")
+
+	broken := dialTestServer(t)
+	broken.Close()
+	c.conn = broken
+
+	failed, err := c.SubscribeL2Book("BTC")
+	if err == nil {
+		t.Fatal("subscribing on a closed connection succeeded")
+	}
+	if failed != nil {
+		t.Error("failed subscription returned a channel")
+	}
+	if _, ok := c.subs["l2Book:BTC"]; ok {
+		t.Fatal("failed subscription left registered")
+	}
+
+	c.conn = dialTestServer(t)
+	books, err := c.SubscribeL2Book("BTC")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	c.dispatch([]byte(`{"channel":"l2Book","data":{"coin":"BTC","time":1,"levels":[[],[]]}}`))
+	select {
+	case book := <-books:
+		if book.Coin != "BTC" {
+			t.Errorf("book = %+v", book)
+		}
+	default:
+		t.Fatal("no book delivered after retry")
+	}
+}